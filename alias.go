@@ -28,12 +28,14 @@ func (r *Router) Alias(name string, aliases, path []string, argTransform ArgTran
 	if !ok {
 		return nil, ErrNilCommand
 	}
+	canonical := append([]string(nil), c.canonicalPath()...)
 	if len(path) > 1 {
 		for _, step := range path[1:] {
 			c, ok = c.subCmds[step]
 			if !ok {
 				return nil, ErrNilCommand
 			}
+			canonical = append(canonical, c.Name)
 		}
 	}
 
@@ -45,19 +47,35 @@ func (r *Router) Alias(name string, aliases, path []string, argTransform ArgTran
 		Description: c.Description,
 		Usage:       c.Usage,
 
-		Args:  c.Args,
-		Flags: c.Flags,
+		Args:   c.Args,
+		Params: c.Params,
+		Flags:  c.Flags,
 
 		Blacklistable:     c.Blacklistable,
 		CustomPermissions: c.CustomPermissions,
 		Permissions:       c.Permissions,
 
-		subCmds: c.subCmds,
+		subCmds:     c.subCmds,
+		middlewares: c.middlewares,
+		aliasOf:     c,
+		aliasPath:   canonical,
+
+		Module: c.Module,
+		Guilds: c.Guilds,
 
 		GuildOnly: c.GuildOnly,
 		OwnerOnly: c.OwnerOnly,
 		Cooldown:  c.Cooldown,
 
+		CooldownUses:   c.CooldownUses,
+		CooldownScope:  c.CooldownScope,
+		CooldownExempt: c.CooldownExempt,
+
+		MaxConcurrency:     c.MaxConcurrency,
+		ConcurrencyScope:   c.ConcurrencyScope,
+		ConcurrencyQueue:   c.ConcurrencyQueue,
+		ConcurrencyTimeout: c.ConcurrencyTimeout,
+
 		Command: func(ctx *Context) (err error) {
 			if argTransform != nil {
 				ctx.RawArgs = argTransform(ctx.RawArgs)
@@ -42,6 +42,11 @@ func (ctx *Context) CheckArgRange(min, max int) (err error) {
 }
 
 func (ctx *Context) argCheck() (err error) {
+	// if the command has declarative parameters, parse those instead
+	if ctx.Cmd.Params != nil {
+		return ctx.parseParams()
+	}
+
 	// if there's no requirements, return
 	if ctx.Cmd.Args == nil {
 		return nil
@@ -50,61 +55,51 @@ func (ctx *Context) argCheck() (err error) {
 	// there's only a minimum number of arguments
 	// if there's too few, show an error
 	if ctx.Cmd.Args[1] == -1 && len(ctx.Args) < ctx.Cmd.Args[0] {
-		_, err = ctx.Sendf(
-			":x: You didn't give enough arguments: this command requires %v arguments, but you gave %v.\n> **Usage:**\n> ```%v%v %v```",
+		return newArgumentError(
+			MsgNotEnoughArgs,
 			ctx.Cmd.Args[0],
 			len(ctx.Args),
-			ctx.Router.Prefixes[0], strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
+			ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
 		)
-		if err != nil {
-			return err
-		}
-		return errCommandRun
 	}
 
 	// there's only a maximum number of arguments
 	// if there's too many, show an error
 	if ctx.Cmd.Args[0] == -1 && len(ctx.Args) > ctx.Cmd.Args[1] {
-		_, err = ctx.Sendf(
-			":x: You gave too many arguments: this command requires at most %v arguments, but you gave %v.\n> **Usage:**\n> ```%v%v %v```",
+		return newArgumentError(
+			MsgTooManyArgs,
 			ctx.Cmd.Args[1],
 			len(ctx.Args),
-			ctx.Router.Prefixes[0], strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
+			ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
 		)
-		if err != nil {
-			return err
-		}
-		return errCommandRun
 	}
 
 	// there's both a minimum and maximum number of arguments
 	if ctx.Cmd.Args[0] != -1 && ctx.Cmd.Args[1] != -1 {
 		if ctx.Cmd.Args[0] == ctx.Cmd.Args[1] && len(ctx.Args) != ctx.Cmd.Args[0] {
-			_, err = ctx.Sendf(
-				":x: This command requires exactly %v arguments, but you gave %v.\n> **Usage:**\n> ```%v%v %v```",
+			return newArgumentError(
+				MsgExactArgs,
 				ctx.Cmd.Args[0],
 				len(ctx.Args),
-				ctx.Router.Prefixes[0], strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
+				ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
 			)
-		} else if len(ctx.Args) < ctx.Cmd.Args[0] {
-			_, err = ctx.Sendf(
-				":x: You didn't give enough arguments: this command requires %v arguments, but you gave %v.\n> **Usage:**\n> ```%v%v %v```",
+		}
+		if len(ctx.Args) < ctx.Cmd.Args[0] {
+			return newArgumentError(
+				MsgNotEnoughArgs,
 				ctx.Cmd.Args[0],
 				len(ctx.Args),
-				ctx.Router.Prefixes[0], strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
+				ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
 			)
-		} else if len(ctx.Args) > ctx.Cmd.Args[1] {
-			_, err = ctx.Sendf(
-				":x: You gave too many arguments: this command requires at most %v arguments, but you gave %v.\n> **Usage:**\n> ```%v%v %v```",
+		}
+		if len(ctx.Args) > ctx.Cmd.Args[1] {
+			return newArgumentError(
+				MsgTooManyArgs,
 				ctx.Cmd.Args[1],
 				len(ctx.Args),
-				ctx.Router.Prefixes[0], strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
+				ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.Usage,
 			)
 		}
-		if err != nil {
-			return err
-		}
-		return errCommandRun
 	}
 
 	// everything's fine, return nil
@@ -0,0 +1,204 @@
+package bcr
+
+import (
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+)
+
+// MaxAutocompleteChoices is the maximum number of choices Discord accepts in an autocomplete response.
+// Any choices over this limit are silently dropped by the Respond* methods.
+const MaxAutocompleteChoices = 25
+
+// AutocompleteFunc is a function that handles autocomplete for a single option.
+type AutocompleteFunc func(*AutocompleteContext) error
+
+// AutocompleteContext is the context passed to an AutocompleteFunc.
+type AutocompleteContext struct {
+	// CommandName is the name of the (sub)command being autocompleted.
+	CommandName string
+	// Options are the options the user has typed so far, at the (sub)command's level.
+	// These may be partial or invalid!
+	Options discord.AutocompleteOptions
+	// Focused is the option currently being typed.
+	Focused discord.AutocompleteOption
+
+	Command *Command
+	Router  *Router
+	State   *state.State
+
+	Author  discord.User
+	Member  *discord.Member
+	GuildID discord.GuildID
+
+	// Event is the original raw event
+	Event *gateway.InteractionCreateEvent
+	Data  *discord.AutocompleteInteraction
+
+	responded bool
+}
+
+// Option returns the named option, or an empty option if it wasn't given.
+func (ctx *AutocompleteContext) Option(name string) discord.AutocompleteOption {
+	return ctx.Options.Find(name)
+}
+
+// Value returns the current value of the focused option as a string.
+func (ctx *AutocompleteContext) Value() string {
+	return ctx.Focused.String()
+}
+
+// Respond responds with the given choices. Choices over MaxAutocompleteChoices are dropped.
+func (ctx *AutocompleteContext) Respond(choices api.AutocompleteChoices) error {
+	ctx.responded = true
+
+	switch c := choices.(type) {
+	case api.AutocompleteStringChoices:
+		if len(c) > MaxAutocompleteChoices {
+			choices = c[:MaxAutocompleteChoices]
+		}
+	case api.AutocompleteIntegerChoices:
+		if len(c) > MaxAutocompleteChoices {
+			choices = c[:MaxAutocompleteChoices]
+		}
+	case api.AutocompleteNumberChoices:
+		if len(c) > MaxAutocompleteChoices {
+			choices = c[:MaxAutocompleteChoices]
+		}
+	}
+
+	return ctx.State.RespondInteraction(ctx.Event.ID, ctx.Event.Token, api.InteractionResponse{
+		Type: api.AutocompleteResult,
+		Data: &api.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+// RespondStrings responds with the given string choices.
+func (ctx *AutocompleteContext) RespondStrings(choices ...discord.StringChoice) error {
+	return ctx.Respond(api.AutocompleteStringChoices(choices))
+}
+
+// RespondValues responds with the given strings, using each string as both the choice's name and value.
+func (ctx *AutocompleteContext) RespondValues(values ...string) error {
+	choices := make([]discord.StringChoice, 0, len(values))
+	for _, v := range values {
+		choices = append(choices, discord.StringChoice{Name: v, Value: v})
+	}
+	return ctx.RespondStrings(choices...)
+}
+
+// RespondInts responds with the given integer choices.
+func (ctx *AutocompleteContext) RespondInts(choices ...discord.IntegerChoice) error {
+	return ctx.Respond(api.AutocompleteIntegerChoices(choices))
+}
+
+// RespondNumbers responds with the given number choices.
+func (ctx *AutocompleteContext) RespondNumbers(choices ...discord.NumberChoice) error {
+	return ctx.Respond(api.AutocompleteNumberChoices(choices))
+}
+
+// executeAutocomplete routes an autocomplete interaction to the correct command's handler.
+func (r *Router) executeAutocomplete(ic *gateway.InteractionCreateEvent) error {
+	data, ok := ic.Data.(*discord.AutocompleteInteraction)
+	if !ok {
+		return nil
+	}
+
+	s, _ := r.StateFromGuildID(ic.GuildID)
+	ctx := &AutocompleteContext{
+		CommandName: data.Name,
+		Options:     data.Options,
+		Router:      r,
+		State:       s,
+		Member:      ic.Member,
+		GuildID:     ic.GuildID,
+		Event:       ic,
+		Data:        data,
+	}
+
+	if ic.Member != nil {
+		ctx.Author = ic.Member.User
+	} else if ic.User != nil {
+		ctx.Author = *ic.User
+	}
+
+	ctx.Command = r.autocompleteCommand(ctx)
+	ctx.Focused = ctx.Options.Focused()
+
+	var fn AutocompleteFunc
+	if ctx.Command != nil {
+		fn = ctx.Command.autocompleteFunc(ctx.Focused.Name)
+	}
+
+	// always respond, otherwise the client shows an error
+	if fn == nil {
+		return ctx.RespondStrings()
+	}
+
+	// autocomplete interactions can't be responded to with a message, so the user isn't shown the incident
+	err := r.safeRun(interactionIncident("autocomplete handler", ctx.Event), nil, func() error {
+		return fn(ctx)
+	})
+	// the handler panicked, which has already been logged
+	if err == errCommandRun {
+		err = nil
+	}
+	if err != nil {
+		if !ctx.responded {
+			ctx.RespondStrings()
+		}
+		return err
+	}
+
+	if !ctx.responded {
+		return ctx.RespondStrings()
+	}
+	return nil
+}
+
+// autocompleteCommand finds the command being autocompleted, descending into groups if needed.
+func (r *Router) autocompleteCommand(ctx *AutocompleteContext) *Command {
+	if len(ctx.Options) > 0 && (ctx.Options[0].Type == discord.SubcommandOptionType || ctx.Options[0].Type == discord.SubcommandGroupOptionType) {
+		for _, g := range r.slashGroups() {
+			if !strings.EqualFold(g.Name, ctx.CommandName) {
+				continue
+			}
+
+			// if this is a subcommand group, descend one more level
+			if ctx.Options[0].Type == discord.SubcommandGroupOptionType {
+				g = g.subgroup(ctx.Options[0].Name)
+				if g == nil || len(ctx.Options[0].Options) == 0 {
+					return nil
+				}
+				ctx.Options = ctx.Options[0].Options
+			}
+
+			ctx.CommandName = ctx.Options[0].Name
+			ctx.Options = ctx.Options[0].Options
+
+			return g.commandMap()[strings.ToLower(ctx.CommandName)]
+		}
+	}
+
+	return r.GetCommand(ctx.CommandName)
+}
+
+// autocompleteFunc returns the autocomplete handler for the named option.
+// Option names are lowercased for slash commands, so names are matched case-insensitively.
+func (c *Command) autocompleteFunc(name string) AutocompleteFunc {
+	if fn, ok := c.Autocomplete[name]; ok {
+		return fn
+	}
+
+	for k, fn := range c.Autocomplete {
+		if strings.EqualFold(k, name) {
+			return fn
+		}
+	}
+	return nil
+}
@@ -26,6 +26,14 @@ type Router struct {
 
 	Prefixes []string
 	Prefixer Prefixer
+	// PrefixOptions configures how DefaultPrefixer matches prefixes.
+	PrefixOptions PrefixOptions
+
+	// SuggestCommands suggests similarly named commands when an unknown command is used.
+	// Unknown subcommands are suggested for commands without Args or Params, instead of running the parent command.
+	SuggestCommands bool
+	// SuggestionButton adds a button to suggestions, which runs the suggested command with the same arguments.
+	SuggestionButton bool
 
 	ShardManager *shard.Manager
 	Bot          *discord.User
@@ -42,12 +50,70 @@ type Router struct {
 
 	ReactTimeout time.Duration
 
-	cooldowns *CooldownCache
-	cmds      map[string]*Command
-	cmdMu     sync.RWMutex
+	// CooldownExempt are the users no command cooldowns apply to, see CooldownExemptions.
+	CooldownExempt CooldownExemptions
+	// ToggleStore stores commands disabled in guilds, channels, or for roles, see Toggle.
+	// It's set to an in-memory store by New. If nil, commands can't be disabled.
+	ToggleStore ToggleStore
+
+	// AntiSpam is the router-wide rate limit for all commands. If nil, there's no limit.
+	AntiSpam *AntiSpam
+
+	// CheckResponder presents failed checks (such as missing permissions or invalid arguments) to the user.
+	// If nil, DefaultCheckResponder is used.
+	CheckResponder CheckResponder
+	// OnError is called with errors returned by commands. It's set to DefaultErrorHandler by New.
+	// If nil, errors are returned from Execute and ExecuteSlash instead.
+	OnError ErrorHandler
+
+	// Panics in commands and handlers are recovered, and the user is shown an incident ID.
+	// OnPanic is called with every recovered panic.
+	OnPanic func(*Incident)
+	// IncidentChannel is the channel recovered panics are reported in, if it's set.
+	IncidentChannel discord.ChannelID
+	// IncidentDMOwners reports recovered panics to all BotOwners in DMs.
+	IncidentDMOwners bool
+
+	// AutoDefer automatically defers slash commands that haven't responded after this duration, see SlashContext.Defer.
+	// If 0, slash commands aren't automatically deferred. This should be under 3 seconds, as interactions expire after that.
+	AutoDefer time.Duration
+	// AutoDeferEphemeral makes automatically deferred responses ephemeral.
+	AutoDeferEphemeral bool
+
+	// Translator translates the router's messages, see Translator.
+	// If nil, all messages are in English.
+	Translator Translator
+	// LocaleFunc returns the language to use for prefix commands in the given guild (or DM, if guildID is 0) for the given user.
+	// Slash commands use the interaction's locale instead.
+	LocaleFunc func(guildID discord.GuildID, userID discord.UserID) discord.Language
+
+	cooldowns   *Cooldowns
+	concurrency *concurrencyLimiter
+	rateLimits  *rateLimiter
+
+	guildPrefixes *GuildPrefixes
+	cmds          map[string]*Command
+	cmdMu         sync.RWMutex
+
+	// user and message commands
+	contextCmds map[contextCmdKey]*Command
+
+	middlewares  []Middleware
+	middlewareMu sync.RWMutex
 
 	SlashGroups []*Group
 
+	// all slash groups, including those derived from commands; built by slashGroups
+	groups      []*Group
+	groupsBuilt bool
+	// groupsGen is the value of subcommandGen the groups were built at
+	groupsGen uint64
+	groupMu   sync.Mutex
+
+	// guilds that scoped commands were synced to, so they can be cleared when they're no longer referenced
+	syncedGuilds  map[discord.GuildID]struct{}
+	syncedGuildMu sync.Mutex
+
 	// maps + mutexes
 	reactions     map[reactionKey]reactionInfo
 	reactionMu    sync.RWMutex
@@ -57,6 +123,10 @@ type Router struct {
 	buttonMu      sync.RWMutex
 	slashButtons  map[buttonKey]slashButtonInfo
 	slashButtonMu sync.RWMutex
+	selects       map[buttonKey]selectInfo
+	selectMu      sync.RWMutex
+	modals        map[modalKey]modalInfo
+	modalMu       sync.RWMutex
 }
 
 // New creates a new router object
@@ -76,15 +146,26 @@ func New(s *shard.Manager, owners, prefixes []string) *Router {
 		ReactTimeout: 15 * time.Minute,
 
 		cmds:         make(map[string]*Command),
+		contextCmds:  make(map[contextCmdKey]*Command),
 		reactions:    make(map[reactionKey]reactionInfo),
 		messages:     make(map[messageKey]messageInfo),
 		buttons:      make(map[buttonKey]buttonInfo),
 		slashButtons: make(map[buttonKey]slashButtonInfo),
-		cooldowns:    newCooldownCache(),
+		selects:      make(map[buttonKey]selectInfo),
+		modals:       make(map[modalKey]modalInfo),
+		syncedGuilds: make(map[discord.GuildID]struct{}),
+		cooldowns:    newCooldowns(),
+		concurrency:  newConcurrencyLimiter(),
+		rateLimits:   newRateLimiter(),
 	}
 
 	// set prefixer
+	r.guildPrefixes = newGuildPrefixes(r)
 	r.Prefixer = r.DefaultPrefixer
+	// set error handler
+	r.OnError = r.DefaultErrorHandler
+	// set toggle store
+	r.ToggleStore = NewMemoryToggleStore()
 
 	// add required handlers
 	r.AddHandler(r.ReactionAdd)
@@ -92,6 +173,8 @@ func New(s *shard.Manager, owners, prefixes []string) *Router {
 	r.AddHandler(r.ReactionMessageDelete)
 	r.AddHandler(r.MsgHandlerCreate)
 	r.AddHandler(r.ButtonHandler)
+	r.AddHandler(r.SelectHandler)
+	r.AddHandler(r.ModalHandler)
 
 	return r
 }
@@ -128,10 +211,23 @@ func (r *Router) AddCommand(c *Command) *Command {
 	if c.Options != nil && c.SlashCommand == nil {
 		panic("command.Options set without command.SlashCommand being set")
 	}
+	c.checkParams()
+
+	r.invalidateSlashGroups()
 
 	c.id = sGen.Get()
 	r.cmdMu.Lock()
 	defer r.cmdMu.Unlock()
+
+	if c.isContextCommand() {
+		if c.SlashCommand == nil {
+			panic("user or message command added without command.SlashCommand being set")
+		}
+
+		r.contextCmds[contextCmdKey{c.Type, c.Name}] = c
+		return c
+	}
+
 	r.cmds[strings.ToLower(c.Name)] = c
 
 	for _, a := range c.Aliases {
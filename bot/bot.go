@@ -57,6 +57,13 @@ func (bot *Bot) Owner(owners ...discord.UserID) {
 func (bot *Bot) Add(f func(*Bot) (string, []*bcr.Command)) {
 	m, c := f(bot)
 
+	// set the commands' module, so they can be disabled together
+	for _, cmd := range c {
+		if cmd.Module == "" {
+			cmd.Module = m
+		}
+	}
+
 	// sort the list of commands
 	sort.Sort(bcr.Commands(c))
 
@@ -0,0 +1,228 @@
+package bcr
+
+import (
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// CheckError is returned when one of the router's built-in checks fails before a command is run.
+// The error is passed through the command's middleware, and then to Router.CheckResponder.
+type CheckError interface {
+	error
+	// Message returns the message shown to the user by the default responder, in the context's language.
+	Message(ctx Contexter) string
+}
+
+// CheckResponder presents a failed check to the user.
+// The returned error is returned from Execute or ExecuteSlash.
+type CheckResponder func(ctx Contexter, err CheckError) error
+
+// GuildOnlyError is returned when a guild-only command is used in DMs.
+type GuildOnlyError struct{}
+
+func (*GuildOnlyError) Error() string { return "command can only be used in guilds" }
+
+// Message implements CheckError.
+func (*GuildOnlyError) Message(ctx Contexter) string { return ctx.Translate(MsgGuildOnly) }
+
+// OwnerOnlyError is returned when an owner-only command is used by someone other than a bot owner.
+type OwnerOnlyError struct{}
+
+func (*OwnerOnlyError) Error() string { return "command can only be used by bot owners" }
+
+// Message implements CheckError.
+func (*OwnerOnlyError) Message(ctx Contexter) string { return ctx.Translate(MsgOwnerOnly) }
+
+// BlacklistedError is returned when a command is used in a channel blocked by Router.BlacklistFunc.
+// The default responder ignores these for prefix commands.
+type BlacklistedError struct{}
+
+func (*BlacklistedError) Error() string { return "command can't be used in this channel" }
+
+// Message implements CheckError.
+func (*BlacklistedError) Message(ctx Contexter) string { return ctx.Translate(MsgCantUseHere) }
+
+// UnavailableError is returned when a slash command scoped to other guilds is used in the current guild.
+type UnavailableError struct{}
+
+func (*UnavailableError) Error() string { return "command isn't available in this guild" }
+
+// Message implements CheckError.
+func (*UnavailableError) Message(ctx Contexter) string { return ctx.Translate(MsgCantUseHere) }
+
+// PermissionError is returned when the user is missing permissions.
+type PermissionError struct {
+	// Missing are the missing Discord permissions.
+	Missing discord.Permissions
+	// Channel is true if the permissions were checked in the current channel, rather than server-wide.
+	Channel bool
+
+	// Custom is the name of the command's CustomPermissions, if those failed.
+	Custom string
+	// Err is the error returned by CustomPermissions, if any.
+	Err error
+
+	// Data is the message returned by Router.PermissionCheck, if that failed.
+	// Custom is set to the name it returned.
+	Data *api.SendMessageData
+}
+
+func (e *PermissionError) Error() string {
+	if e.Err != nil {
+		return "checking permission " + e.Custom + ": " + e.Err.Error()
+	}
+	if e.Custom != "" {
+		return "missing permission " + e.Custom
+	}
+	return "missing permissions " + strings.Join(PermStrings(e.Missing), ", ")
+}
+
+// Message implements CheckError.
+func (e *PermissionError) Message(ctx Contexter) string {
+	if e.Data != nil {
+		return e.Data.Content
+	}
+	if e.Err != nil {
+		return ctx.Translate(MsgCustomPermsError, e.Custom)
+	}
+	if e.Custom != "" {
+		return ctx.Translate(MsgMissingCustomPerms, e.Custom)
+	}
+
+	if r := contextRouter(ctx); r != nil {
+		return ctx.Translate(MsgMissingPerms, strings.Join(r.PermStrings(ctx.Locale(), e.Missing), ", "))
+	}
+	return ctx.Translate(MsgMissingPerms, strings.Join(PermStrings(e.Missing), ", "))
+}
+
+// Unwrap returns the error returned by CustomPermissions, if any.
+func (e *PermissionError) Unwrap() error { return e.Err }
+
+// CooldownError is returned when a command is on cooldown.
+type CooldownError struct {
+	// Cooldown is the command's cooldown window.
+	Cooldown time.Duration
+	// Uses is the number of uses allowed per window.
+	Uses int
+	// Scope is the scope of the cooldown.
+	Scope CooldownScope
+	// Remaining is the time until the command can be used again.
+	Remaining time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return "command is on cooldown for " + e.Remaining.String()
+}
+
+// Message implements CheckError.
+func (e *CooldownError) Message(ctx Contexter) string {
+	if r := contextRouter(ctx); r != nil {
+		return ctx.Translate(MsgCooldown, r.HumanizeDuration(ctx.Locale(), DurationPrecisionSeconds, e.Remaining))
+	}
+	return ctx.Translate(MsgCooldown, HumanizeDuration(DurationPrecisionSeconds, e.Remaining))
+}
+
+// FlagError is returned when a command's flags couldn't be parsed.
+type FlagError struct {
+	Err error
+}
+
+func (e *FlagError) Error() string { return "parsing flags: " + e.Err.Error() }
+
+// Message implements CheckError.
+func (e *FlagError) Message(ctx Contexter) string { return ctx.Translate(MsgFlagError) }
+
+// Unwrap returns the underlying error.
+func (e *FlagError) Unwrap() error { return e.Err }
+
+// ArgumentError is returned when a command's arguments are invalid.
+type ArgumentError struct {
+	// Param is the name of the parameter that was missing or couldn't be parsed, if any.
+	Param string
+	// Value is the input that couldn't be parsed, if any.
+	Value string
+	// Err is the underlying parsing error, if any.
+	Err error
+
+	key  string
+	args []interface{}
+}
+
+func newArgumentError(key string, args ...interface{}) *ArgumentError {
+	return &ArgumentError{key: key, args: args}
+}
+
+func (e *ArgumentError) Error() string {
+	switch {
+	case e.Err != nil && e.Param != "":
+		return "parsing argument " + e.Param + ": " + e.Err.Error()
+	case e.Err != nil:
+		return "parsing arguments: " + e.Err.Error()
+	case e.Param != "":
+		return "missing argument " + e.Param
+	}
+	return "invalid number of arguments"
+}
+
+// Message implements CheckError.
+func (e *ArgumentError) Message(ctx Contexter) string { return ctx.Translate(e.key, e.args...) }
+
+// Unwrap returns the underlying error.
+func (e *ArgumentError) Unwrap() error { return e.Err }
+
+// contextRouter returns the router of a *Context or *SlashContext, or nil for other Contexters.
+func contextRouter(ctx Contexter) *Router {
+	switch ctx := ctx.(type) {
+	case *Context:
+		return ctx.Router
+	case *SlashContext:
+		return ctx.Router
+	}
+	return nil
+}
+
+// DefaultCheckResponder sends the error's message: as a normal message for prefix commands, and as an ephemeral message for slash commands.
+// BlacklistedErrors and DisabledErrors are ignored for prefix commands,
+// and PermissionErrors from Router.PermissionCheck send the message it returned.
+func DefaultCheckResponder(ctx Contexter, err CheckError) error {
+	if pctx, ok := ctx.(*Context); ok {
+		switch e := err.(type) {
+		case *BlacklistedError, *DisabledError:
+			return nil
+		case *PermissionError:
+			// Router.PermissionCheck's message may have more than just content
+			if e.Data != nil {
+				_, err := pctx.State.SendMessageComplex(pctx.Message.ChannelID, *e.Data)
+				return err
+			}
+		}
+
+		_, err := ctx.Send(err.Message(ctx))
+		return err
+	}
+
+	return ctx.SendEphemeral(err.Message(ctx))
+}
+
+// respondCheck passes err to the router's CheckResponder if it's a CheckError.
+// Returns errCommandRun if the error was handled.
+func (r *Router) respondCheck(ctx Contexter, err error) error {
+	var checkErr CheckError
+	if !errors.As(err, &checkErr) {
+		return err
+	}
+
+	respond := r.CheckResponder
+	if respond == nil {
+		respond = DefaultCheckResponder
+	}
+
+	if err := respond(ctx, checkErr); err != nil {
+		return err
+	}
+	return errCommandRun
+}
@@ -3,6 +3,7 @@ package bcr
 import (
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/diamondburned/arikawa/v3/discord"
@@ -34,10 +35,21 @@ type Command struct {
 	// Usage is appended to the command name in help commands
 	Usage string
 
+	// NameLocalizations and DescriptionLocalizations are the slash command's name and Summary in other languages.
+	NameLocalizations        discord.StringLocales
+	DescriptionLocalizations discord.StringLocales
+
 	// Hidden commands are not shown in the help command
 	Hidden bool
 
+	// Module is the name of the module the command is in, used to disable whole modules with toggles.
+	// It's set automatically for commands added to a bot.Bot.
+	Module string
+
 	Args *Args
+	// Params is a declarative list of the command's parameters.
+	// If set, it's used instead of Args to check arguments, and to generate Usage and slash command Options if those are unset.
+	Params []Param
 
 	CustomPermissions CustomPerms
 
@@ -48,6 +60,8 @@ type Command struct {
 	subCmds map[string]*Command
 	subMu   sync.RWMutex
 
+	middlewares []Middleware
+
 	// GuildPermissions is the required *global* permissions
 	GuildPermissions discord.Permissions
 	// Permissions is the required permissions in the *context channel*
@@ -55,27 +69,100 @@ type Command struct {
 	GuildOnly   bool
 	OwnerOnly   bool
 
-	Command  func(*Context) error
+	Command func(*Context) error
+
+	// Cooldown is the window for the command's cooldown, during which the command can be used CooldownUses times.
+	// Cooldowns apply to both prefix and slash commands.
 	Cooldown time.Duration
+	// CooldownUses is the number of times the command can be used per Cooldown. If 0, it can be used once.
+	CooldownUses int
+	// CooldownScope is the scope the cooldown applies to, see CooldownScope.
+	CooldownScope CooldownScope
+	// CooldownExempt are the users this command's cooldown doesn't apply to, in addition to Router.CooldownExempt.
+	CooldownExempt CooldownExemptions
+
+	// MaxConcurrency is the number of invocations of the command that can run at once, per ConcurrencyScope.
+	// If 0, there's no limit. Applies to both prefix and slash commands.
+	MaxConcurrency int
+	// ConcurrencyScope is the scope the concurrency limit applies to, see ConcurrencyScope.
+	ConcurrencyScope ConcurrencyScope
+	// ConcurrencyQueue is the number of invocations that can wait for a running one to finish.
+	// If 0, invocations over the limit are rejected immediately.
+	ConcurrencyQueue int
+	// ConcurrencyTimeout is how long a queued invocation waits before being rejected. If 0, it waits until it can run.
+	// Slash commands wait at most 14 minutes either way, as interaction tokens expire after 15.
+	ConcurrencyTimeout time.Duration
+
+	// AutoDefer overrides Router.AutoDefer for this command. If negative, the command is never automatically deferred.
+	AutoDefer time.Duration
+
+	// untoggleable commands can't be disabled with toggles
+	untoggleable bool
+
+	// aliasOf is the command this command is an alias to, if it was created with Router.Alias
+	aliasOf *Command
+	// aliasPath is the path to the command this command is an alias to, using the commands' names
+	aliasPath []string
 
 	// id is a unique ID. This is automatically generated on startup and is (pretty much) guaranteed to be unique *per session*. This ID will *not* be consistent between restarts.
 	id snowflake.Snowflake
 
 	// Executed when a slash command is executed, with a *SlashContext being passed in.
 	// Also executed when Command is nil, with a *Context being passed in instead.
+	//
+	// If any of a command's subcommands have SlashCommand set, the command is synced as a slash command with subcommands
+	// (and subcommand groups, for subcommands with slash subcommands of their own),
+	// and its own SlashCommand and Options are ignored for slash commands.
 	SlashCommand func(Contexter) error
 	// If this is set and SlashCommand is nil, AddCommand *will panic!*
 	// Even if the command has no options, this should be set to an empty slice rather than nil.
 	Options *[]discord.CommandOption
+
+	// Guilds limits the command to the given guilds.
+	// Prefix invocations outside these guilds are ignored, and slash commands are only synced in these guilds.
+	// If empty, the command is available everywhere.
+	Guilds []discord.GuildID
+
+	// Type is the type of application command this is registered as.
+	// If this is discord.UserCommand or discord.MessageCommand, the command is shown in the "Apps" context menu instead,
+	// isn't available as a prefix command, and can't have options. SlashCommand must be set for these commands.
+	// Their names are case-sensitive and can contain spaces.
+	// Defaults to discord.ChatInputCommand.
+	Type discord.CommandType
+
+	// Autocomplete maps option names to their autocomplete handlers.
+	// Options generated from Params automatically have autocomplete enabled if they have a handler here;
+	// options set manually in Options must set Autocomplete themselves.
+	Autocomplete map[string]AutocompleteFunc
+}
+
+// availableIn returns true if the command can be used in the given guild.
+func (c *Command) availableIn(guildID discord.GuildID) bool {
+	return len(c.Guilds) == 0 || guildInSlice(guildID, c.Guilds)
+}
+
+// canonicalPath returns the path cooldowns and concurrency limits for a top-level command are keyed by.
+func (c *Command) canonicalPath() []string {
+	if c.aliasPath != nil {
+		return c.aliasPath
+	}
+	return []string{c.Name}
 }
 
+// subcommandGen is incremented whenever a subcommand is added to any command.
+// Subcommands don't know their router, so routers use this to rebuild their slash groups.
+var subcommandGen uint64
+
 // AddSubcommand adds a subcommand to a command
 func (c *Command) AddSubcommand(sub *Command) *Command {
 	if c.Options != nil && c.SlashCommand == nil {
 		panic("command.Options set without command.SlashCommand being set")
 	}
+	sub.checkParams()
 
 	sub.id = sGen.Get()
+	defer atomic.AddUint64(&subcommandGen, 1)
+
 	c.subMu.Lock()
 	defer c.subMu.Unlock()
 	if c.subCmds == nil {
@@ -0,0 +1,185 @@
+package bcr
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// ConcurrencyScope is the scope a command's concurrency limit applies to.
+type ConcurrencyScope int
+
+// Concurrency scopes
+const (
+	// ConcurrencyGlobal limits the number of invocations running at once across all users. This is the default.
+	ConcurrencyGlobal ConcurrencyScope = iota
+	// ConcurrencyGuild limits the number of invocations running at once per guild. In DMs, it applies per channel.
+	ConcurrencyGuild
+	// ConcurrencyUser limits the number of invocations running at once per user.
+	ConcurrencyUser
+)
+
+func (s ConcurrencyScope) String() string {
+	switch s {
+	case ConcurrencyGuild:
+		return "guild"
+	case ConcurrencyUser:
+		return "user"
+	}
+	return "global"
+}
+
+// concurrencyKey returns the key for the given command path and scope.
+func concurrencyKey(path []string, scope ConcurrencyScope, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID) string {
+	key := strings.ToLower(strings.Join(path, " ")) + ":" + scope.String()
+
+	switch scope {
+	case ConcurrencyGuild:
+		if !guildID.IsValid() {
+			return key + ":" + channelID.String()
+		}
+		return key + ":" + guildID.String()
+	case ConcurrencyUser:
+		return key + ":" + userID.String()
+	}
+	return key
+}
+
+// ConcurrencyError is returned when a command is already running the maximum number of times.
+type ConcurrencyError struct {
+	// Max is the number of invocations allowed to run at once.
+	Max   int
+	Scope ConcurrencyScope
+	// TimedOut is true if the invocation was queued, but timed out before it could run.
+	TimedOut bool
+}
+
+func (e *ConcurrencyError) Error() string {
+	if e.TimedOut {
+		return "timed out waiting for command to be available"
+	}
+	return "command is already running the maximum number of times"
+}
+
+// Message implements CheckError.
+func (e *ConcurrencyError) Message(ctx Contexter) string {
+	if e.TimedOut {
+		return ctx.Translate(MsgConcurrencyTimeout)
+	}
+	return ctx.Translate(MsgConcurrencyLimit)
+}
+
+// maxSlashQueueWait is the longest a queued slash command waits, as interaction tokens expire after 15 minutes.
+const maxSlashQueueWait = 14 * time.Minute
+
+// concurrencySlot is a semaphore for one concurrency key.
+type concurrencySlot struct {
+	sem chan struct{}
+	// waiting is the number of queued invocations
+	waiting int
+	// refs is the number of running and queued invocations; the slot is deleted when it reaches 0
+	refs int
+}
+
+// concurrencyLimiter tracks running invocations of commands with a concurrency limit.
+type concurrencyLimiter struct {
+	mu    sync.Mutex
+	slots map[string]*concurrencySlot
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{slots: make(map[string]*concurrencySlot)}
+}
+
+// acquire waits for a slot for the command to run in.
+// If none are free, the invocation is queued if the queue isn't full, and onQueue is called.
+// Queued invocations wait at most maxWait, if it's non-zero, even if the command's ConcurrencyTimeout is longer.
+// The returned function must be called when the command is done.
+func (l *concurrencyLimiter) acquire(key string, c *Command, maxWait time.Duration, onQueue func()) (release func(), err error) {
+	l.mu.Lock()
+	s, ok := l.slots[key]
+	if !ok {
+		s = &concurrencySlot{sem: make(chan struct{}, c.MaxConcurrency)}
+		l.slots[key] = s
+	}
+	s.refs++
+
+	select {
+	case s.sem <- struct{}{}:
+		l.mu.Unlock()
+		return l.releaseFunc(key, s), nil
+	default:
+	}
+
+	if s.waiting >= c.ConcurrencyQueue {
+		l.unref(key, s)
+		l.mu.Unlock()
+		return nil, &ConcurrencyError{Max: c.MaxConcurrency, Scope: c.ConcurrencyScope}
+	}
+	s.waiting++
+	l.mu.Unlock()
+
+	if onQueue != nil {
+		onQueue()
+	}
+
+	wait := c.ConcurrencyTimeout
+	if maxWait > 0 && (wait <= 0 || wait > maxWait) {
+		wait = maxWait
+	}
+
+	var timeout <-chan time.Time
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case s.sem <- struct{}{}:
+		l.mu.Lock()
+		s.waiting--
+		l.mu.Unlock()
+		return l.releaseFunc(key, s), nil
+	case <-timeout:
+		l.mu.Lock()
+		s.waiting--
+		l.unref(key, s)
+		l.mu.Unlock()
+		return nil, &ConcurrencyError{Max: c.MaxConcurrency, Scope: c.ConcurrencyScope, TimedOut: true}
+	}
+}
+
+func (l *concurrencyLimiter) releaseFunc(key string, s *concurrencySlot) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-s.sem
+
+			l.mu.Lock()
+			l.unref(key, s)
+			l.mu.Unlock()
+		})
+	}
+}
+
+// unref decrements the slot's reference count, deleting it if it's unused. l.mu must be held.
+func (l *concurrencyLimiter) unref(key string, s *concurrencySlot) {
+	s.refs--
+	if s.refs <= 0 && l.slots[key] == s {
+		delete(l.slots, key)
+	}
+}
+
+// acquireConcurrency waits for the command to be allowed to run, if it has a concurrency limit.
+// The returned function must be called when the command is done.
+func (r *Router) acquireConcurrency(c *Command, path []string, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID, maxWait time.Duration, onQueue func()) (release func(), err error) {
+	if c.MaxConcurrency <= 0 {
+		return func() {}, nil
+	}
+
+	key := concurrencyKey(path, c.ConcurrencyScope, userID, channelID, guildID)
+	return r.concurrency.acquire(key, c, maxWait, onQueue)
+}
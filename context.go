@@ -25,14 +25,12 @@ var (
 // Note that this function should still use the built-in r.Prefixes for mention prefixes
 type Prefixer func(m discord.Message) int
 
-// DefaultPrefixer ...
-func (r *Router) DefaultPrefixer(m discord.Message) int {
-	for _, p := range r.Prefixes {
-		if strings.HasPrefix(strings.ToLower(m.Content), p) {
-			return len(p)
-		}
+// usagePrefix returns the prefix shown in usage hints: the guild's first prefix, or the prefix used if there are none.
+func (ctx *Context) usagePrefix() string {
+	if prefixes := ctx.Router.GuildPrefixes().Get(ctx.Message.GuildID); len(prefixes) != 0 {
+		return prefixes[0]
 	}
-	return -1
+	return ctx.Prefix
 }
 
 var _ Contexter = (*Context)(nil)
@@ -45,11 +43,18 @@ type Context struct {
 	Prefix  string
 
 	FullCommandPath []string
+	// commandPath is the path to the invoked command, using the commands' names instead of the aliases used
+	commandPath []string
+	// limitPath is commandPath, but with aliases created with Router.Alias replaced by the path of the command they alias.
+	// Toggles, cooldowns, and concurrency limits use this, so an alias shares them with its command.
+	limitPath []string
 
 	Args    []string
 	RawArgs string
 
 	Flags *pflag.FlagSet
+	// Params are the parsed values of the command's Params, if any
+	Params ParamValues
 
 	InternalArgs []string
 	pos          int
@@ -82,15 +87,10 @@ type Context struct {
 
 // NewContext returns a new message context
 func (r *Router) NewContext(m *gateway.MessageCreateEvent) (ctx *Context, err error) {
-	messageContent := m.Content
-
-	var p int
-	if p = r.Prefixer(m.Message); p != -1 {
-		messageContent = messageContent[p:]
-	} else {
+	prefix, messageContent, ok := r.matchPrefix(m.Message)
+	if !ok {
 		return nil, ErrEmptyMessage
 	}
-	messageContent = strings.TrimSpace(messageContent)
 
 	message, err := shellwords.Parse(messageContent)
 	if err != nil {
@@ -110,7 +110,7 @@ func (r *Router) NewContext(m *gateway.MessageCreateEvent) (ctx *Context, err er
 	// create the context
 	ctx = &Context{
 		Command: command,
-		Prefix:  m.Content[:p],
+		Prefix:  prefix,
 
 		InternalArgs:     args,
 		Args:             args,
@@ -0,0 +1,83 @@
+package bcr
+
+import (
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+type contextCmdKey struct {
+	typ  discord.CommandType
+	name string
+}
+
+// isContextCommand returns true if the command is a user or message command.
+func (c *Command) isContextCommand() bool {
+	return c.Type == discord.UserCommand || c.Type == discord.MessageCommand
+}
+
+// ContextCommands returns a list of all user and message commands.
+func (r *Router) ContextCommands() []*Command {
+	r.cmdMu.RLock()
+	defer r.cmdMu.RUnlock()
+
+	cmds := make([]*Command, 0, len(r.contextCmds))
+	for _, c := range r.contextCmds {
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+// IsContextCommand returns true if the context was created from a user or message command.
+func (ctx *SlashContext) IsContextCommand() bool {
+	return ctx.Data.TargetID.IsValid()
+}
+
+// targetType returns the type of context menu command this context was created from.
+func (ctx *SlashContext) targetType() discord.CommandType {
+	if _, ok := ctx.Data.Resolved.Messages[ctx.Data.TargetMessageID()]; ok {
+		return discord.MessageCommand
+	}
+	return discord.UserCommand
+}
+
+// resolveTargets fills the context's Target* fields from the interaction's resolved data.
+func (ctx *SlashContext) resolveTargets() {
+	if !ctx.IsContextCommand() {
+		return
+	}
+
+	if m, ok := ctx.Data.Resolved.Messages[ctx.Data.TargetMessageID()]; ok {
+		ctx.TargetMessage = &m
+		return
+	}
+
+	if u, ok := ctx.Data.Resolved.Users[ctx.Data.TargetUserID()]; ok {
+		ctx.TargetUser = &u
+
+		// resolved members are missing their user, so add it back
+		if m, ok := ctx.Data.Resolved.Members[ctx.Data.TargetUserID()]; ok {
+			m.User = u
+			ctx.TargetMember = &m
+		}
+	}
+}
+
+func (r *Router) executeContextCommand(ctx *SlashContext) (err error) {
+	r.cmdMu.RLock()
+	cmd, ok := r.contextCmds[contextCmdKey{ctx.targetType(), ctx.CommandName}]
+	r.cmdMu.RUnlock()
+
+	if !ok {
+		err = ctx.SendEphemeral(ctx.Translate(MsgBrokenContextCommand, EscapeBackticks(ctx.CommandName)))
+		return errCommand(err)
+	}
+
+	// if the command is disabled here, stop.
+	// its name can contain spaces, so it's split the same way as toggles' command paths.
+	if err = r.checkToggles(ctx, ctx.Event.GuildID, strings.Fields(cmd.Name), cmd); err != nil {
+		return errCommand(r.respondCheck(ctx, err))
+	}
+
+	return r.execSlashCommand(ctx, cmd, nil)
+}
@@ -50,6 +50,13 @@ type Contexter interface {
 	GetParentChannel() *discord.Channel
 	// GetMember returns this context's Member
 	GetMember() *discord.Member
+	// GetParams returns this context's parsed parameters
+	GetParams() ParamValues
+
+	// Locale returns the language used for this context's messages
+	Locale() discord.Language
+	// Translate returns the message with the given key in the context's language
+	Translate(key string, args ...interface{}) string
 
 	// ButtonPages paginates a slice of embeds using buttons
 	ButtonPages(embeds []discord.Embed, timeout time.Duration) (msg *discord.Message, rmFunc func(), err error)
@@ -66,6 +73,8 @@ type SlashContext struct {
 	CommandID      discord.CommandID
 	CommandName    string
 	CommandOptions []discord.CommandInteractionOption
+	// CommandPath is the full path to the invoked command, including any groups (for example, "config", "roles", "add").
+	CommandPath []string
 
 	InteractionID    discord.InteractionID
 	InteractionToken string
@@ -85,7 +94,18 @@ type SlashContext struct {
 	Event *gateway.InteractionCreateEvent
 	Data  *discord.CommandInteraction
 
+	// Params are the parsed values of the command's Params, if any
+	Params ParamValues
+
+	// The target of a user or message command. For user commands, TargetMember is only filled in guilds.
+	TargetUser    *discord.User
+	TargetMember  *discord.Member
+	TargetMessage *discord.Message
+
 	AdditionalParams map[string]interface{}
+
+	// resp tracks whether the interaction was responded to
+	resp *slashResponse
 }
 
 // Session returns this SlashContext's state.
@@ -116,13 +136,17 @@ func (r *Router) NewSlashContext(ic *gateway.InteractionCreateEvent) (*SlashCont
 		Event:            ic,
 		Data:             data,
 		CommandName:      data.Name,
+		CommandPath:      []string{data.Name},
 		CommandID:        data.ID,
 		CommandOptions:   data.Options,
 		InteractionID:    ic.ID,
 		InteractionToken: ic.Token,
 		AdditionalParams: map[string]interface{}{},
+		resp:             &slashResponse{},
 	}
 
+	sc.resolveTargets()
+
 	if ic.Member != nil {
 		sc.Member = ic.Member
 		sc.Author = ic.Member.User
@@ -167,23 +191,21 @@ func (ctx *SlashContext) Thread() bool {
 	return ctx.Channel.Type == discord.GuildNewsThread || ctx.Channel.Type == discord.GuildPublicThread || ctx.Channel.Type == discord.GuildPrivateThread
 }
 
-// SendX sends a message without returning the created discord.Message
+// SendX sends a message without returning the created discord.Message.
+// If the interaction was already responded to, the message is sent as a follow-up; see Respond.
 func (ctx *SlashContext) SendX(content string, embeds ...discord.Embed) (err error) {
-	data := api.InteractionResponse{
-		Type: api.MessageInteractionWithSource,
-		Data: &api.InteractionResponseData{
-			AllowedMentions: ctx.Router.DefaultMentions,
-		},
+	data := api.InteractionResponseData{
+		AllowedMentions: ctx.Router.DefaultMentions,
 	}
 
 	if len(embeds) != 0 {
-		data.Data.Embeds = &embeds
+		data.Embeds = &embeds
 	}
 	if content != "" {
-		data.Data.Content = option.NewNullableString(content)
+		data.Content = option.NewNullableString(content)
 	}
 
-	err = ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, data)
+	_, err = ctx.respond(data, false)
 	return
 }
 
@@ -194,55 +216,50 @@ func (ctx *SlashContext) SendfX(format string, args ...interface{}) (err error)
 
 // SendFiles sends a message with attachments
 func (ctx *SlashContext) SendFiles(content string, files ...sendpart.File) (err error) {
-	data := api.InteractionResponse{
-		Type: api.MessageInteractionWithSource,
-		Data: &api.InteractionResponseData{
-			AllowedMentions: ctx.Router.DefaultMentions,
-		},
+	data := api.InteractionResponseData{
+		AllowedMentions: ctx.Router.DefaultMentions,
 	}
 
 	if len(files) != 0 {
-		data.Data.Files = files
+		data.Files = files
 	}
 	if content != "" {
-		data.Data.Content = option.NewNullableString(content)
+		data.Content = option.NewNullableString(content)
 	}
 
-	err = ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, data)
+	_, err = ctx.respond(data, false)
 	return
 }
 
 // SendEphemeral sends an ephemeral message.
+// If the interaction was already responded to, the message is sent as an ephemeral follow-up; see Respond.
 func (ctx *SlashContext) SendEphemeral(content string, embeds ...discord.Embed) (err error) {
-	data := api.InteractionResponse{
-		Type: api.MessageInteractionWithSource,
-		Data: &api.InteractionResponseData{
-			AllowedMentions: ctx.Router.DefaultMentions,
-			Flags:           api.EphemeralResponse,
-		},
+	data := api.InteractionResponseData{
+		AllowedMentions: ctx.Router.DefaultMentions,
+		Flags:           api.EphemeralResponse,
 	}
 
 	if len(embeds) != 0 {
-		data.Data.Embeds = &embeds
+		data.Embeds = &embeds
 	}
 	if content != "" {
-		data.Data.Content = option.NewNullableString(content)
+		data.Content = option.NewNullableString(content)
 	}
 
-	err = ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, data)
+	_, err = ctx.respond(data, false)
 	return
 }
 
 // Original returns the original response to an interaction, if any.
 func (ctx *SlashContext) Original() (msg *discord.Message, err error) {
-	url := api.EndpointWebhooks + ctx.Router.Bot.ID.String() + "/" + ctx.InteractionToken + "/messages/@original"
+	url := api.EndpointWebhooks + ctx.Event.AppID.String() + "/" + ctx.InteractionToken + "/messages/@original"
 
 	return msg, ctx.State.RequestJSON(&msg, "GET", url)
 }
 
 // EditOriginal edits the original response.
 func (ctx *SlashContext) EditOriginal(data api.EditInteractionResponseData) (*discord.Message, error) {
-	return ctx.State.EditInteractionResponse(discord.AppID(ctx.Router.Bot.ID), ctx.Event.Token, data)
+	return ctx.State.EditInteractionResponse(ctx.Event.AppID, ctx.Event.Token, data)
 }
 
 // GetGuild ...
@@ -260,24 +277,26 @@ func (ctx *SlashContext) User() discord.User { return ctx.Author }
 // GetMember ...
 func (ctx *SlashContext) GetMember() *discord.Member { return ctx.Member }
 
-// Send ...
+// Send sends a message, returning the created message.
+// If the interaction was already responded to, the message is sent as a follow-up; see Respond.
 func (ctx *SlashContext) Send(content string, embeds ...discord.Embed) (msg *discord.Message, err error) {
-	err = ctx.SendX(content, embeds...)
-	if err != nil {
-		return
+	data := api.InteractionResponseData{
+		AllowedMentions: ctx.Router.DefaultMentions,
 	}
 
-	return ctx.Original()
+	if len(embeds) != 0 {
+		data.Embeds = &embeds
+	}
+	if content != "" {
+		data.Content = option.NewNullableString(content)
+	}
+
+	return ctx.respond(data, true)
 }
 
 // Sendf ...
 func (ctx *SlashContext) Sendf(tmpl string, args ...interface{}) (msg *discord.Message, err error) {
-	err = ctx.SendfX(tmpl, args...)
-	if err != nil {
-		return
-	}
-
-	return ctx.Original()
+	return ctx.Send(fmt.Sprintf(tmpl, args...))
 }
 
 // GuildPerms returns the global (guild) permissions of this Context's user.
@@ -309,20 +328,10 @@ func (ctx *SlashContext) GuildPerms() (perms discord.Permissions) {
 
 // SendComponents sends a message with components
 func (ctx *SlashContext) SendComponents(components discord.ContainerComponents, content string, embeds ...discord.Embed) (*discord.Message, error) {
-	data := api.InteractionResponse{
-		Type: api.MessageInteractionWithSource,
-		Data: &api.InteractionResponseData{
-			AllowedMentions: ctx.Router.DefaultMentions,
-			Content:         option.NewNullableString(content),
-			Embeds:          &embeds,
-			Components:      &components,
-		},
-	}
-
-	err := ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, data)
-	if err != nil {
-		return nil, err
-	}
-
-	return ctx.Original()
+	return ctx.respond(api.InteractionResponseData{
+		AllowedMentions: ctx.Router.DefaultMentions,
+		Content:         option.NewNullableString(content),
+		Embeds:          &embeds,
+		Components:      &components,
+	}, true)
 }
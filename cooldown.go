@@ -1,40 +1,342 @@
 package bcr
 
 import (
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/ReneKroon/ttlcache/v2"
 	"github.com/diamondburned/arikawa/v3/discord"
 )
 
-// CooldownCache holds cooldowns for commands
-type CooldownCache struct {
-	c *ttlcache.Cache
+// CooldownScope is the scope a command's cooldown applies to.
+type CooldownScope int
+
+// Cooldown scopes
+const (
+	// CooldownUserChannel applies the cooldown per user, per channel. This is the default.
+	CooldownUserChannel CooldownScope = iota
+	// CooldownUser applies the cooldown per user, everywhere.
+	CooldownUser
+	// CooldownMember applies the cooldown per user, per guild. In DMs, it applies per user.
+	CooldownMember
+	// CooldownChannel applies the cooldown to everyone in a channel.
+	CooldownChannel
+	// CooldownGuild applies the cooldown to everyone in a guild. In DMs, it applies per channel.
+	CooldownGuild
+	// CooldownGlobal applies the cooldown to everyone, everywhere.
+	CooldownGlobal
+)
+
+func (s CooldownScope) String() string {
+	switch s {
+	case CooldownUser:
+		return "user"
+	case CooldownMember:
+		return "member"
+	case CooldownChannel:
+		return "channel"
+	case CooldownGuild:
+		return "guild"
+	case CooldownGlobal:
+		return "global"
+	}
+	return "user-channel"
+}
+
+// CooldownKey returns the cooldown bucket key for the given command path and scope.
+func CooldownKey(path []string, scope CooldownScope, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID) string {
+	key := strings.ToLower(strings.Join(path, " ")) + ":" + scope.String()
+
+	switch scope {
+	case CooldownUser:
+		return key + ":" + userID.String()
+	case CooldownMember:
+		if !guildID.IsValid() {
+			return key + ":" + userID.String()
+		}
+		return key + ":" + guildID.String() + ":" + userID.String()
+	case CooldownChannel:
+		return key + ":" + channelID.String()
+	case CooldownGuild:
+		if !guildID.IsValid() {
+			return key + ":" + channelID.String()
+		}
+		return key + ":" + guildID.String()
+	case CooldownGlobal:
+		return key
+	}
+	return key + ":" + userID.String() + ":" + channelID.String()
+}
+
+// CooldownBucket is a command's cooldown for one scope, such as a user in a channel.
+type CooldownBucket struct {
+	// Key is the bucket's key, see CooldownKey.
+	Key string `json:"key"`
+	// Path is the command path the cooldown is for.
+	Path  []string      `json:"path"`
+	Scope CooldownScope `json:"scope"`
+
+	// UserID, ChannelID, and GuildID are the IDs the bucket is scoped to, if any.
+	UserID    discord.UserID    `json:"user_id,omitempty"`
+	ChannelID discord.ChannelID `json:"channel_id,omitempty"`
+	GuildID   discord.GuildID   `json:"guild_id,omitempty"`
+
+	// Uses is the number of times the command has been used in the current window.
+	Uses int `json:"uses"`
+	// Reset is when the current window ends.
+	Reset time.Time `json:"reset"`
+}
+
+// newCooldownBucket returns an empty bucket for the given command path and scope.
+// Only the IDs the scope applies to are set.
+func newCooldownBucket(path []string, scope CooldownScope, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID) CooldownBucket {
+	b := CooldownBucket{
+		Key:   CooldownKey(path, scope, userID, channelID, guildID),
+		Path:  append([]string(nil), path...),
+		Scope: scope,
+	}
+
+	switch scope {
+	case CooldownUser:
+		b.UserID = userID
+	case CooldownMember:
+		b.UserID = userID
+		b.GuildID = guildID
+	case CooldownChannel:
+		b.ChannelID = channelID
+	case CooldownGuild:
+		if guildID.IsValid() {
+			b.GuildID = guildID
+		} else {
+			b.ChannelID = channelID
+		}
+	case CooldownGlobal:
+	default:
+		b.UserID = userID
+		b.ChannelID = channelID
+	}
+	return b
 }
 
-func newCooldownCache() *CooldownCache {
-	cache := ttlcache.NewCache()
-	cache.SkipTTLExtensionOnHit(true)
+// Expired returns true if the bucket's window has ended.
+func (b CooldownBucket) Expired() bool {
+	return !time.Now().Before(b.Reset)
+}
+
+// CooldownCache stores cooldown buckets.
+// The default is an in-memory cache (see NewMemoryCooldownCache), which is cleared when the bot restarts;
+// use NewFileCooldownCache or your own implementation to keep cooldowns across restarts.
+//
+// Implementations must be safe for concurrent use, and should not return expired buckets.
+type CooldownCache interface {
+	// Get returns the bucket with the given key. ok is false if it doesn't exist or has expired.
+	Get(key string) (b CooldownBucket, ok bool, err error)
+	// Set stores the bucket, replacing any existing bucket with the same key.
+	Set(b CooldownBucket) error
+	// Delete deletes the bucket with the given key.
+	Delete(key string) error
+	// Buckets returns all unexpired buckets.
+	Buckets() ([]CooldownBucket, error)
+}
 
-	return &CooldownCache{c: cache}
+// Cooldowns manages the router's command cooldowns.
+type Cooldowns struct {
+	mu    sync.Mutex
+	cache CooldownCache
 }
 
-// Set sets a cooldown for a command
-func (c *CooldownCache) Set(cmdName string, userID discord.UserID, channelID discord.ChannelID, cooldown time.Duration) {
+func newCooldowns() *Cooldowns {
+	return &Cooldowns{cache: NewMemoryCooldownCache()}
+}
+
+// Cooldowns returns the router's cooldown manager.
+func (r *Router) Cooldowns() *Cooldowns {
+	return r.cooldowns
+}
+
+// Cache returns the cache cooldowns are stored in.
+func (c *Cooldowns) Cache() CooldownCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache
+}
+
+// SetCache sets the cache cooldowns are stored in. Existing cooldowns are not copied to the new cache.
+func (c *Cooldowns) SetCache(cache CooldownCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache = cache
+}
+
+// Reserve checks the bucket and records a use of it in one step, so concurrent uses can't both pass the check.
+// If the bucket has no uses left, the time until it can be used again is returned, and no use is recorded.
+// Otherwise, release undoes the use; call it if the command didn't run successfully.
+func (c *Cooldowns) Reserve(b CooldownBucket, uses int, window time.Duration) (remaining time.Duration, release func() error, err error) {
+	release = func() error { return nil }
 	// if the command's cooldown is 0, return
-	if cooldown == 0 {
-		return
+	if window <= 0 {
+		return 0, release, nil
 	}
+	if uses < 1 {
+		uses = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old, ok, err := c.cache.Get(b.Key)
+	if err != nil {
+		return 0, release, err
+	}
+	if ok && !old.Expired() {
+		if old.Uses >= uses {
+			return time.Until(old.Reset), release, nil
+		}
+		b.Uses, b.Reset = old.Uses, old.Reset
+	} else {
+		b.Uses, b.Reset = 0, time.Now().Add(window)
+	}
+	b.Uses++
 
-	c.c.SetWithTTL(cmdName+userID.String()+channelID.String(), true, cooldown)
-	return
+	if err = c.cache.Set(b); err != nil {
+		return 0, release, err
+	}
+	return 0, func() error { return c.release(b) }, nil
 }
 
-// Get returns true if the command is on cooldown
-func (c *CooldownCache) Get(cmdName string, userID discord.UserID, channelID discord.ChannelID) bool {
-	if _, e := c.c.Get(cmdName + userID.String() + channelID.String()); e == nil {
+// release undoes a use recorded by Reserve, if the bucket's window hasn't ended since.
+func (c *Cooldowns) release(b CooldownBucket) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cur, ok, err := c.cache.Get(b.Key)
+	if err != nil || !ok || !cur.Reset.Equal(b.Reset) || cur.Uses < 1 {
+		return err
+	}
+
+	cur.Uses--
+	if cur.Uses == 0 {
+		return c.cache.Delete(cur.Key)
+	}
+	return c.cache.Set(cur)
+}
+
+// Buckets returns all active cooldowns.
+func (c *Cooldowns) Buckets() ([]CooldownBucket, error) {
+	return c.Cache().Buckets()
+}
+
+// User returns the user's active cooldowns.
+// Cooldowns that apply to everyone in a channel or guild, or globally, are not included.
+func (c *Cooldowns) User(userID discord.UserID) ([]CooldownBucket, error) {
+	buckets, err := c.Buckets()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []CooldownBucket
+	for _, b := range buckets {
+		if b.UserID == userID {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// Clear clears the cooldown with the given key.
+func (c *Cooldowns) Clear(key string) error {
+	return c.Cache().Delete(key)
+}
+
+// ClearUser clears all of the user's cooldowns, and returns the number of cooldowns cleared.
+func (c *Cooldowns) ClearUser(userID discord.UserID) (n int, err error) {
+	buckets, err := c.User(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, b := range buckets {
+		if err = c.Clear(b.Key); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// reserveCooldown reserves a use of the command's cooldown bucket, see Cooldowns.Reserve.
+// Cache errors are logged, and the command is allowed to run.
+func (r *Router) reserveCooldown(c *Command, path []string, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID) (remaining time.Duration, release func()) {
+	b := c.cooldownBucket(path, userID, channelID, guildID)
+
+	remaining, releaseBucket, err := r.cooldowns.Reserve(b, c.CooldownUses, c.Cooldown)
+	if err != nil {
+		r.Logger.Error("reserving cooldown %v: %v", b.Key, err)
+	}
+
+	return remaining, func() {
+		if err := releaseBucket(); err != nil {
+			r.Logger.Error("releasing cooldown %v: %v", b.Key, err)
+		}
+	}
+}
+
+// cooldownBucket returns the command's cooldown bucket.
+func (c *Command) cooldownBucket(path []string, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID) CooldownBucket {
+	return newCooldownBucket(path, c.CooldownScope, userID, channelID, guildID)
+}
+
+// CooldownExemptions are users who aren't affected by a cooldown. A user is exempt if they match any of the conditions.
+type CooldownExemptions struct {
+	// Owners exempts the bot owners (Router.BotOwners).
+	Owners bool
+	// Roles exempts members with any of these roles.
+	Roles []discord.RoleID
+	// Permissions exempts members with all of these server-wide permissions. Ignored if 0.
+	Permissions discord.Permissions
+}
+
+// exempts returns true if the context's user is exempt.
+func (e CooldownExemptions) exempts(r *Router, ctx Contexter) bool {
+	if e.Owners && r.isOwner(ctx.User().ID) {
 		return true
 	}
 
+	m := ctx.GetMember()
+	if m == nil {
+		return false
+	}
+
+	for _, id := range e.Roles {
+		for _, role := range m.RoleIDs {
+			if id == role {
+				return true
+			}
+		}
+	}
+
+	if e.Permissions != 0 {
+		if p, ok := ctx.(interface{ GuildPerms() discord.Permissions }); ok && p.GuildPerms().Has(e.Permissions) {
+			return true
+		}
+	}
+	return false
+}
+
+// cooldownExempt returns true if the context's user is exempt from the command's cooldown,
+// either through the command's or the router's exemptions.
+func (r *Router) cooldownExempt(ctx Contexter, c *Command) bool {
+	return c.CooldownExempt.exempts(r, ctx) || r.CooldownExempt.exempts(r, ctx)
+}
+
+// isOwner returns true if the user is one of the bot owners.
+func (r *Router) isOwner(id discord.UserID) bool {
+	for _, u := range r.BotOwners {
+		if u == id.String() {
+			return true
+		}
+	}
 	return false
 }
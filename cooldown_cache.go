@@ -0,0 +1,230 @@
+package bcr
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+// MemoryCooldownCache is an in-memory CooldownCache. Cooldowns are lost when the bot restarts.
+type MemoryCooldownCache struct {
+	mu      sync.Mutex
+	buckets map[string]CooldownBucket
+	pruned  time.Time
+}
+
+var _ CooldownCache = (*MemoryCooldownCache)(nil)
+
+// NewMemoryCooldownCache returns a new, empty in-memory cooldown cache.
+func NewMemoryCooldownCache() *MemoryCooldownCache {
+	return &MemoryCooldownCache{buckets: make(map[string]CooldownBucket)}
+}
+
+// Get implements CooldownCache.
+func (c *MemoryCooldownCache) Get(key string) (CooldownBucket, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.buckets[key]
+	if !ok || b.Expired() {
+		return CooldownBucket{}, false, nil
+	}
+	return b, true, nil
+}
+
+// Set implements CooldownCache.
+func (c *MemoryCooldownCache) Set(b CooldownBucket) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// prune expired buckets at most once a minute, so the map doesn't grow forever
+	if time.Since(c.pruned) > time.Minute {
+		pruneBuckets(c.buckets)
+		c.pruned = time.Now()
+	}
+
+	c.buckets[b.Key] = b
+	return nil
+}
+
+// Delete implements CooldownCache.
+func (c *MemoryCooldownCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.buckets, key)
+	return nil
+}
+
+// Buckets implements CooldownCache.
+func (c *MemoryCooldownCache) Buckets() ([]CooldownBucket, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return activeBuckets(c.buckets), nil
+}
+
+// FileCooldownCache is a CooldownCache stored in a JSON file, so cooldowns are kept across restarts.
+//
+// The file is reloaded whenever it's changed on disk, so multiple processes can share a file.
+// Writes aren't locked between processes, so two processes using the same command at the same time can lose one of the uses.
+type FileCooldownCache struct {
+	path string
+
+	mu      sync.Mutex
+	buckets map[string]CooldownBucket
+	modTime time.Time
+	size    int64
+}
+
+var _ CooldownCache = (*FileCooldownCache)(nil)
+
+// NewFileCooldownCache returns a cooldown cache stored in the file at path.
+// The file is created on the first write if it doesn't exist.
+func NewFileCooldownCache(path string) (*FileCooldownCache, error) {
+	c := &FileCooldownCache{
+		path:    path,
+		buckets: make(map[string]CooldownBucket),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// reload reads the file if its modification time or size changed since it was last read.
+// c.mu must be held.
+func (c *FileCooldownCache) reload() error {
+	fi, err := os.Stat(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "stat cooldown file")
+	}
+	if fi.ModTime().Equal(c.modTime) && fi.Size() == c.size {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return errors.Wrap(err, "reading cooldown file")
+	}
+
+	buckets := make(map[string]CooldownBucket)
+	if len(b) != 0 {
+		if err = json.Unmarshal(b, &buckets); err != nil {
+			return errors.Wrap(err, "decoding cooldown file")
+		}
+	}
+
+	c.buckets = buckets
+	c.modTime, c.size = fi.ModTime(), fi.Size()
+	return nil
+}
+
+// save prunes expired buckets and writes the cache to the file.
+// The file is replaced atomically, so other processes never read a partial file.
+// c.mu must be held.
+func (c *FileCooldownCache) save() error {
+	pruneBuckets(c.buckets)
+
+	b, err := json.Marshal(c.buckets)
+	if err != nil {
+		return errors.Wrap(err, "encoding cooldowns")
+	}
+
+	if err = writeFileAtomic(c.path, b); err != nil {
+		return errors.Wrap(err, "writing cooldown file")
+	}
+
+	if fi, err := os.Stat(c.path); err == nil {
+		c.modTime, c.size = fi.ModTime(), fi.Size()
+	}
+	return nil
+}
+
+// Get implements CooldownCache.
+func (c *FileCooldownCache) Get(key string) (CooldownBucket, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.reload(); err != nil {
+		return CooldownBucket{}, false, err
+	}
+
+	b, ok := c.buckets[key]
+	if !ok || b.Expired() {
+		return CooldownBucket{}, false, nil
+	}
+	return b, true, nil
+}
+
+// Set implements CooldownCache.
+func (c *FileCooldownCache) Set(b CooldownBucket) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.reload(); err != nil {
+		return err
+	}
+
+	c.buckets[b.Key] = b
+	return c.save()
+}
+
+// Delete implements CooldownCache.
+func (c *FileCooldownCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.reload(); err != nil {
+		return err
+	}
+
+	if _, ok := c.buckets[key]; !ok {
+		return nil
+	}
+
+	delete(c.buckets, key)
+	return c.save()
+}
+
+// Buckets implements CooldownCache.
+func (c *FileCooldownCache) Buckets() ([]CooldownBucket, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return activeBuckets(c.buckets), nil
+}
+
+// pruneBuckets deletes expired buckets from m.
+func pruneBuckets(m map[string]CooldownBucket) {
+	for k, b := range m {
+		if b.Expired() {
+			delete(m, k)
+		}
+	}
+}
+
+// activeBuckets returns the unexpired buckets in m.
+func activeBuckets(m map[string]CooldownBucket) []CooldownBucket {
+	out := make([]CooldownBucket, 0, len(m))
+	for _, b := range m {
+		if !b.Expired() {
+			out = append(out, b)
+		}
+	}
+	return out
+}
@@ -244,7 +244,10 @@ func (r *Router) ButtonHandler(ev *gateway.InteractionCreateEvent) {
 		return
 	}
 
-	info.fn(info.ctx, ev)
+	r.safeRun(interactionIncident("button handler", ev), r.replyInteraction(ev), func() error {
+		info.fn(info.ctx, ev)
+		return nil
+	})
 
 	if info.delete {
 		r.buttonMu.Lock()
@@ -267,7 +270,10 @@ func (r *Router) slashButton(ev *gateway.InteractionCreateEvent, user discord.Us
 		return
 	}
 
-	info.fn(info.ctx, ev)
+	r.safeRun(interactionIncident("button handler", ev), r.replyInteraction(ev), func() error {
+		info.fn(info.ctx, ev)
+		return nil
+	})
 
 	if info.delete {
 		r.slashButtonMu.Lock()
@@ -55,18 +55,10 @@ func (ctx *SlashContext) ButtonPagesWithComponents(embeds []discord.Embed, timeo
 	ctx.AdditionalParams["page"] = 0
 
 	if len(embeds) == 1 {
-		err = ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, api.InteractionResponse{
-			Type: api.MessageInteractionWithSource,
-			Data: &api.InteractionResponseData{
-				Embeds:     &[]discord.Embed{embeds[0]},
-				Components: &components,
-			},
-		})
-		if err != nil {
-			return
-		}
-
-		msg, err = ctx.Original()
+		msg, err = ctx.respond(api.InteractionResponseData{
+			Embeds:     &[]discord.Embed{embeds[0]},
+			Components: &components,
+		}, true)
 		return
 	}
 
@@ -108,19 +100,14 @@ func (ctx *SlashContext) ButtonPagesWithComponents(embeds []discord.Embed, timeo
 		},
 	})
 
-	err = ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, api.InteractionResponse{
-		Type: api.MessageInteractionWithSource,
-		Data: &api.InteractionResponseData{
-			Embeds:     &[]discord.Embed{embeds[0]},
-			Components: &components,
-		},
-	})
+	msg, err = ctx.respond(api.InteractionResponseData{
+		Embeds:     &[]discord.Embed{embeds[0]},
+		Components: &components,
+	}, true)
 	if err != nil {
 		return
 	}
 
-	msg, err = ctx.Original()
-
 	page := 0
 
 	prev := ctx.AddButtonHandler(msg.ID, ctx.Author.ID, "prev", false, func(ctx *SlashContext, ev *gateway.InteractionCreateEvent) {
@@ -254,39 +241,30 @@ func (ctx *SlashContext) ConfirmButton(userID discord.UserID, data ConfirmData)
 	con, cancel := context.WithTimeout(context.Background(), data.Timeout)
 	defer cancel()
 
-	err := ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, api.InteractionResponse{
-		Type: api.MessageInteractionWithSource,
-		Data: &api.InteractionResponseData{
-			Content: option.NewNullableString(data.Message),
-			Embeds:  &data.Embeds,
-
-			Components: &discord.ContainerComponents{
-				&discord.ActionRowComponent{
-					&discord.ButtonComponent{
-						Label:    data.YesPrompt,
-						Style:    data.YesStyle,
-						CustomID: "yes",
-					},
-					&discord.ButtonComponent{
-						Label:    data.NoPrompt,
-						Style:    data.NoStyle,
-						CustomID: "no",
-					},
+	msg, err := ctx.respond(api.InteractionResponseData{
+		Content: option.NewNullableString(data.Message),
+		Embeds:  &data.Embeds,
+
+		Components: &discord.ContainerComponents{
+			&discord.ActionRowComponent{
+				&discord.ButtonComponent{
+					Label:    data.YesPrompt,
+					Style:    data.YesStyle,
+					CustomID: "yes",
+				},
+				&discord.ButtonComponent{
+					Label:    data.NoPrompt,
+					Style:    data.NoStyle,
+					CustomID: "no",
 				},
 			},
 		},
-	})
+	}, true)
 	if err != nil {
 		ctx.Router.Logger.Error("error sending interaction: %v", err)
 		return
 	}
 
-	msg, err := ctx.Original()
-	if err != nil {
-		ctx.Router.Logger.Error("error retrieving original: %v", err)
-		return
-	}
-
 	v := ctx.State.WaitFor(con, func(ev interface{}) bool {
 		v, ok := ev.(*gateway.InteractionCreateEvent)
 		if !ok {
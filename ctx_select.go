@@ -0,0 +1,170 @@
+package bcr
+
+import (
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+)
+
+// SelectValues are the values selected in a select menu.
+// Values is always filled; the other fields are only filled for the matching select menu type,
+// and only contain values that could be resolved.
+type SelectValues struct {
+	Type discord.ComponentType
+
+	// Values are the raw selected values: the option values for string selects, and IDs for other selects.
+	Values []string
+
+	Users    []discord.User
+	Members  []discord.Member
+	Roles    []discord.Role
+	Channels []discord.Channel
+}
+
+type selectInfo struct {
+	fn     func(*gateway.InteractionCreateEvent, *SelectValues)
+	delete bool
+}
+
+// AddSelectHandler adds a select menu handler for the given message ID, user ID, and custom ID
+func (ctx *Context) AddSelectHandler(
+	msg discord.MessageID,
+	user discord.UserID,
+	customID discord.ComponentID,
+	del bool,
+	fn func(*Context, *gateway.InteractionCreateEvent, *SelectValues),
+) ButtonRemoveFunc {
+	return ctx.Router.addSelectHandler(buttonKey{msg, user, customID}, del, func(ev *gateway.InteractionCreateEvent, v *SelectValues) {
+		fn(ctx, ev, v)
+	})
+}
+
+// AddSelectHandler adds a select menu handler for the given message ID, user ID, and custom ID
+func (ctx *SlashContext) AddSelectHandler(
+	msg discord.MessageID,
+	user discord.UserID,
+	customID discord.ComponentID,
+	del bool,
+	fn func(*SlashContext, *gateway.InteractionCreateEvent, *SelectValues),
+) ButtonRemoveFunc {
+	return ctx.Router.addSelectHandler(buttonKey{msg, user, customID}, del, func(ev *gateway.InteractionCreateEvent, v *SelectValues) {
+		fn(ctx, ev, v)
+	})
+}
+
+func (r *Router) addSelectHandler(key buttonKey, del bool, fn func(*gateway.InteractionCreateEvent, *SelectValues)) ButtonRemoveFunc {
+	r.selectMu.Lock()
+	defer r.selectMu.Unlock()
+
+	r.selects[key] = selectInfo{fn, del}
+
+	return func() {
+		r.selectMu.Lock()
+		delete(r.selects, key)
+		r.selectMu.Unlock()
+	}
+}
+
+// SelectHandler handles select menus added by ctx.AddSelectHandler
+func (r *Router) SelectHandler(ev *gateway.InteractionCreateEvent) {
+	if ev.Data.InteractionType() != discord.ComponentInteractionType {
+		return
+	}
+
+	if ev.Message == nil ||
+		(ev.Member == nil && ev.User == nil) ||
+		ev.Data == nil {
+		return
+	}
+	data, ok := ev.Data.(discord.ComponentInteraction)
+	if !ok || data.ID() == "" {
+		return
+	}
+
+	switch data.Type() {
+	case discord.StringSelectComponentType, discord.UserSelectComponentType, discord.RoleSelectComponentType,
+		discord.ChannelSelectComponentType, discord.MentionableSelectComponentType:
+	default:
+		return
+	}
+
+	key := buttonKey{ev.Message.ID, ev.SenderID(), data.ID()}
+
+	r.selectMu.RLock()
+	info, ok := r.selects[key]
+	r.selectMu.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	s, _ := r.StateFromGuildID(ev.GuildID)
+	r.safeRun(interactionIncident("select menu handler", ev), r.replyInteraction(ev), func() error {
+		info.fn(ev, resolveSelect(s, ev.GuildID, data))
+		return nil
+	})
+
+	if info.delete {
+		r.selectMu.Lock()
+		delete(r.selects, key)
+		r.selectMu.Unlock()
+	}
+}
+
+// resolveSelect resolves the values of a select interaction.
+// Values that can't be resolved are skipped.
+func resolveSelect(s *state.State, guildID discord.GuildID, data discord.ComponentInteraction) *SelectValues {
+	v := &SelectValues{Type: data.Type()}
+
+	switch data := data.(type) {
+	case *discord.StringSelectInteraction:
+		v.Values = data.Values
+	case *discord.UserSelectInteraction:
+		for _, id := range data.Values {
+			v.Values = append(v.Values, id.String())
+			v.resolveUser(s, guildID, id)
+		}
+	case *discord.RoleSelectInteraction:
+		for _, id := range data.Values {
+			v.Values = append(v.Values, id.String())
+			if r, err := s.Role(guildID, id); err == nil {
+				v.Roles = append(v.Roles, *r)
+			}
+		}
+	case *discord.ChannelSelectInteraction:
+		for _, id := range data.Values {
+			v.Values = append(v.Values, id.String())
+			if ch, err := s.Channel(id); err == nil {
+				v.Channels = append(v.Channels, *ch)
+			}
+		}
+	case *discord.MentionableSelectInteraction:
+		for _, id := range data.Values {
+			v.Values = append(v.Values, id.String())
+			// mentionables are either roles or users, so try roles first
+			if guildID.IsValid() {
+				if r, err := s.Role(guildID, discord.RoleID(id)); err == nil {
+					v.Roles = append(v.Roles, *r)
+					continue
+				}
+			}
+			v.resolveUser(s, guildID, discord.UserID(id))
+		}
+	}
+
+	return v
+}
+
+func (v *SelectValues) resolveUser(s *state.State, guildID discord.GuildID, id discord.UserID) {
+	if guildID.IsValid() {
+		if m, err := s.Member(guildID, id); err == nil {
+			v.Members = append(v.Members, *m)
+			v.Users = append(v.Users, m.User)
+			return
+		}
+	}
+
+	if u, err := s.User(id); err == nil {
+		v.Users = append(v.Users, *u)
+	}
+}
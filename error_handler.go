@@ -0,0 +1,74 @@
+package bcr
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+// ErrorHandler handles errors returned by commands.
+// path is the full path to the command, including any parent commands or groups.
+type ErrorHandler func(ctx Contexter, path []string, err error)
+
+// UserError is an error whose message is shown to the user as-is by the default error handler.
+// Return one from a command to show an error message without having to send it yourself.
+type UserError struct {
+	// Message is shown to the user.
+	Message string
+	// Err is the underlying error, if any. It is logged, but not shown to the user.
+	Err error
+}
+
+// NewUserError returns a new *UserError with the given message.
+func NewUserError(format string, args ...interface{}) *UserError {
+	return &UserError{Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *UserError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e *UserError) Unwrap() error { return e.Err }
+
+// DefaultErrorHandler is the router's default error handler.
+// It shows the message of a *UserError as-is, logging its underlying error if it has one; any other error is logged,
+// and the user is sent a generic error message.
+func (r *Router) DefaultErrorHandler(ctx Contexter, path []string, err error) {
+	var userErr *UserError
+	if errors.As(err, &userErr) {
+		if userErr.Err != nil {
+			r.Logger.Error("executing command %v: %v", strings.Join(path, " "), userErr.Err)
+		}
+
+		if err := ctx.SendEphemeral(userErr.Message); err != nil {
+			r.Logger.Error("sending error message: %v", err)
+		}
+		return
+	}
+
+	r.Logger.Error("executing command %v: %v", strings.Join(path, " "), err)
+
+	if err := ctx.SendEphemeral(ctx.Translate(MsgCommandError)); err != nil {
+		r.Logger.Error("sending error message: %v", err)
+	}
+}
+
+// handleError passes errors returned by a command to the router's error handler.
+// Returns errCommandRun if the error was handled.
+func (r *Router) handleError(ctx Contexter, path []string, err error) error {
+	if err == nil || err == errCommandRun {
+		return err
+	}
+
+	if r.OnError == nil {
+		return err
+	}
+
+	r.OnError(ctx, path, err)
+	return errCommandRun
+}
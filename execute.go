@@ -2,8 +2,6 @@ package bcr
 
 import (
 	"errors"
-	"fmt"
-	"strings"
 	"sync"
 
 	"github.com/diamondburned/arikawa/v3/discord"
@@ -12,16 +10,17 @@ import (
 
 var errCommandRun = errors.New("command run in layer")
 
-// Execute executes the command router
+// Execute executes the command router.
+// Errors returned by the command are passed to Router.OnError, if it's set, and aren't returned.
 func (r *Router) Execute(ctx *Context) (err error) {
-	err = r.execInner(ctx, r.cmds, &r.cmdMu)
+	err = r.execInner(ctx, r.cmds, &r.cmdMu, nil)
 	if err == errCommandRun {
 		return nil
 	}
 	return err
 }
 
-func (r *Router) execInner(ctx *Context, cmds map[string]*Command, mu *sync.RWMutex) (err error) {
+func (r *Router) execInner(ctx *Context, cmds map[string]*Command, mu *sync.RWMutex, mws []Middleware) (err error) {
 	var (
 		c  *Command
 		ok bool
@@ -30,12 +29,38 @@ func (r *Router) execInner(ctx *Context, cmds map[string]*Command, mu *sync.RWMu
 	// check if a command matches, if not, return
 	if c, ok = cmds[ctx.Command]; !ok {
 		mu.RUnlock()
+
+		// if enabled, suggest a similarly named command
+		if r.SuggestCommands {
+			if s := suggestCommand(ctx.Command, cmds, mu, ctx.Message.GuildID); s != nil {
+				return errCommand(r.suggest(ctx, ctx.Command, s, cmds, mu, mws))
+			}
+		}
 		return
 	}
 	mu.RUnlock()
 
+	// if the command is limited to other guilds, treat it as if it doesn't exist
+	if !c.availableIn(ctx.Message.GuildID) {
+		return
+	}
+
 	// append the current command to FullCommandPath, for help strings
 	ctx.FullCommandPath = append(ctx.FullCommandPath, ctx.Command)
+	ctx.commandPath = append(ctx.commandPath, c.Name)
+	if c.aliasPath != nil {
+		ctx.limitPath = append([]string(nil), c.aliasPath...)
+	} else {
+		ctx.limitPath = append(ctx.limitPath, c.Name)
+	}
+
+	// if the command is disabled here, stop
+	if !leadsToUntoggleable(c, ctx.Args) {
+		if err = r.checkToggles(ctx, ctx.Message.GuildID, ctx.limitPath, c); err != nil {
+			return errCommand(r.respondCheck(ctx, err))
+		}
+	}
+
 	// check if the second argument is `help` or `usage`, if so, show the command's help
 	err = ctx.tryHelp()
 	if err != nil {
@@ -44,118 +69,115 @@ func (r *Router) execInner(ctx *Context, cmds map[string]*Command, mu *sync.RWMu
 
 	// if the command has subcommands, try those
 	if c.subCmds != nil && len(ctx.Args) > 0 {
-		if _, ok = c.subCmds[ctx.Peek()]; ok {
+		if sub, ok := c.subCmds[ctx.Peek()]; ok && sub.availableIn(ctx.Message.GuildID) {
 			ctx.Command = ctx.Pop()
-			err = r.execInner(ctx, c.subCmds, &c.subMu)
+			// the parent command's middleware is also run for its subcommands
+			err = r.execInner(ctx, c.subCmds, &c.subMu, append(mws[:len(mws):len(mws)], c.middlewares...))
 			// return all errors, including errCommandRun, so further layers stop executing as well
 			if err != nil {
 				return err
 			}
+		} else if r.SuggestCommands && c.Args == nil && c.Params == nil {
+			// the command doesn't take arguments, so this is most likely a misspelled subcommand
+			if s := suggestCommand(ctx.Peek(), c.subCmds, &c.subMu, ctx.Message.GuildID); s != nil {
+				typo := ctx.Pop()
+				return errCommand(r.suggest(ctx, typo, s, c.subCmds, &c.subMu, append(mws[:len(mws):len(mws)], c.middlewares...)))
+			}
 		}
 	}
 
 	// set the context's Cmd field to the command
 	ctx.Cmd = c
 
+	// run the command wrapped in all applicable middleware, recovering from any panics
+	err = r.safeRun(commandIncident("command", ctx), ctx.sendFunc(), func() error {
+		return chain(func(c *Command, _ Contexter) error {
+			err := r.runCommand(ctx, c)
+			if err == errCommandRun {
+				return nil
+			}
+			return err
+		}, r.routerMiddleware(), mws, c.middlewares)(c, ctx)
+	})
+
+	// if a check failed, let the router's responder handle it
+	err = r.respondCheck(ctx, err)
+	// and if the command itself errored, the router's error handler
+	err = r.handleError(ctx, ctx.FullCommandPath, err)
+
+	// return with errCommandRun, which indicates to an outer layer (if any) that it should stop execution
+	return errCommand(err)
+}
+
+// runCommand runs the built-in checks for a command, and then the command itself.
+// Failed checks return a CheckError.
+func (r *Router) runCommand(ctx *Context, c *Command) (err error) {
 	// if the command is guild-only or needs extra permissions, and this isn't a guild channel, error
 	if (c.GuildOnly || c.Permissions != 0) && ctx.Message.GuildID == 0 {
-		_, err = ctx.Send(":x: This command cannot be run in DMs.")
-		if err != nil {
-			return err
-		}
-		return errCommandRun
+		return &GuildOnlyError{}
 	}
 
 	// check if the command can be blacklisted
 	if r.BlacklistFunc != nil && c.Blacklistable {
 		// if the channel's blacklisted, return
 		if r.BlacklistFunc(ctx) {
-			return errCommandRun
+			return &BlacklistedError{}
 		}
 	}
 
 	// if the command requires bot owner to use, and the user isn't a bot owner, error
 	if !ctx.checkOwner() {
-		_, err = ctx.Send(":x: This command can only be used by a bot owner.")
-		if err != nil {
-			return err
-		}
-		return errCommandRun
+		return &OwnerOnlyError{}
 	}
 
 	if c.GuildPermissions != 0 {
 		if ctx.Guild == nil || ctx.Member == nil {
-			_, err = ctx.Send(":x: This command cannot be used in DMs.")
-			return errCommandRun
+			return &GuildOnlyError{}
 		}
-		if !ctx.GuildPerms().Has(c.GuildPermissions) {
-			_, err = ctx.Sendf(":x: You are not allowed to use this command. You are missing the following permissions:\n> ```%v```", strings.Join(PermStrings(c.GuildPermissions), ", "))
-			// if there's an error, return it
-			if err != nil {
-				return err
-			}
-			// but if not, return errCommandRun so we don't try running more
-			return errCommandRun
+		if perms := ctx.GuildPerms(); !perms.Has(c.GuildPermissions) {
+			return &PermissionError{Missing: c.GuildPermissions &^ perms}
 		}
 	}
 
 	if c.Permissions != 0 {
 		if ctx.Guild == nil || ctx.Channel == nil || ctx.Member == nil {
-			_, err = ctx.Send(":x: This command cannot be used in DMs.")
-			return errCommandRun
+			return &GuildOnlyError{}
 		}
-		if !discord.CalcOverrides(*ctx.Guild, *ctx.Channel, *ctx.Member, ctx.Guild.Roles).Has(c.Permissions) {
-			_, err = ctx.Sendf(":x: You are not allowed to use this command. You are missing the following permissions:\n> ```%v```", strings.Join(PermStrings(c.Permissions), ", "))
-			// if there's an error, return it
-			if err != nil {
-				return err
-			}
-			// but if not, return errCommandRun so we don't try running more
-			return errCommandRun
+		if perms := discord.CalcOverrides(*ctx.Guild, *ctx.Channel, *ctx.Member, ctx.Guild.Roles); !perms.Has(c.Permissions) {
+			return &PermissionError{Missing: c.Permissions &^ perms, Channel: true}
 		}
 	}
 
 	// if the command has a custom permission handler, check it
 	if c.CustomPermissions != nil {
 		b, err := c.CustomPermissions.Check(ctx)
-		// if it errored, send that error and return
-		if err != nil {
-			_, err = ctx.Send(fmt.Sprintf(":x: An internal error occurred when checking your permissions.\nThe following permission(s) could not be checked:\n> ```%s```", c.CustomPermissions.String(ctx)))
-			if err != nil {
-				return err
-			}
-			return errCommandRun
-		}
-
-		// else if it returned false, show that error and return
-		if !b {
-			_, err = ctx.Send(fmt.Sprintf(":x: You are not allowed to use this command. You are missing the following permission(s):\n> ```%s```", c.CustomPermissions.String(ctx)))
-			if err != nil {
-				return err
-			}
-			return errCommandRun
+		// if it errored, or returned false, return a permission error
+		if err != nil || !b {
+			return &PermissionError{Custom: c.CustomPermissions.String(ctx), Err: err}
 		}
 	}
 
 	// check router-level permissions
 	// (usually, custom permission systems)
 	if r.PermissionCheck != nil {
-		_, allowed, data := r.PermissionCheck(ctx, true)
+		name, allowed, data := r.PermissionCheck(ctx, true)
 		if !allowed {
-			if _, err = ctx.State.SendMessageComplex(ctx.Channel.ID, data); err != nil {
-				return err
-			}
-			return errCommandRun
+			return &PermissionError{Custom: name, Data: &data}
 		}
 	}
 
-	// check for a cooldown
-	if r.cooldowns.Get(strings.Join(ctx.FullCommandPath, "-"), ctx.Author.ID, ctx.Channel.ID) {
-		_, err = ctx.Sendf(":x: This command can only be run once every %v.", c.Cooldown)
-		if err != nil {
-			return err
+	// check for a cooldown, and reserve a use of it
+	if !r.cooldownExempt(ctx, c) {
+		remaining, releaseCooldown := r.reserveCooldown(c, ctx.limitPath, ctx.Author.ID, ctx.Message.ChannelID, ctx.Message.GuildID)
+		if remaining > 0 {
+			return &CooldownError{Cooldown: c.Cooldown, Uses: c.CooldownUses, Scope: c.CooldownScope, Remaining: remaining}
 		}
-		return errCommandRun
+		// if the command doesn't run successfully, it shouldn't count towards the cooldown
+		defer func() {
+			if err != nil {
+				releaseCooldown()
+			}
+		}()
 	}
 
 	// if the command has any flags set, parse those
@@ -165,8 +187,7 @@ func (r *Router) execInner(ctx *Context, cmds map[string]*Command, mu *sync.RWMu
 
 		err = ctx.Flags.Parse(ctx.Args)
 		if err != nil {
-			_, err = ctx.Send(":x: There was an error parsing your input. Try checking this command's help.")
-			return
+			return &FlagError{Err: err}
 		}
 		ctx.Args = ctx.Flags.Args()
 	}
@@ -177,19 +198,17 @@ func (r *Router) execInner(ctx *Context, cmds map[string]*Command, mu *sync.RWMu
 		return err
 	}
 
+	// wait for the command to be available, if it has a concurrency limit
+	release, err := r.acquireConcurrency(c, ctx.limitPath, ctx.Author.ID, ctx.Message.ChannelID, ctx.Message.GuildID, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	if c.Command != nil {
 		err = c.Command(ctx)
 	} else {
 		err = c.SlashCommand(ctx)
 	}
-	if err != nil {
-		return err
-	}
-	// if there's a cooldown, set it
-	if c.Cooldown != 0 {
-		r.cooldowns.Set(strings.Join(ctx.FullCommandPath, "-"), ctx.Author.ID, ctx.Channel.ID, c.Cooldown)
-	}
-
-	// return with errCommandRun, which indicates to an outer layer (if any) that it should stop execution
-	return errCommandRun
+	return err
 }
@@ -1,7 +1,6 @@
 package bcr
 
 import (
-	"fmt"
 	"strings"
 	"sync"
 
@@ -11,10 +10,26 @@ import (
 
 // InteractionCreate is called when an interaction create event is received.
 func (r *Router) InteractionCreate(ic *gateway.InteractionCreateEvent) {
+	if ic.Data.InteractionType() == discord.AutocompleteInteractionType {
+		err := r.executeAutocomplete(ic)
+		if err != nil {
+			r.Logger.Error("Couldn't run autocomplete: %v", err)
+		}
+		return
+	}
+
 	if ic.Data.InteractionType() != discord.CommandInteractionType {
 		return
 	}
 
+	lang := ic.Locale
+	if lang == "" {
+		lang = discord.Language(ic.GuildLocale)
+	}
+	if r.rateLimited(ic.SenderID(), ic.ChannelID, ic.GuildID, lang, r.replyInteraction(ic), r.ackInteraction(ic)) {
+		return
+	}
+
 	ctx, err := r.NewSlashContext(ic)
 	if err != nil {
 		r.Logger.Error("Couldn't create slash context: %v", err)
@@ -27,9 +42,10 @@ func (r *Router) InteractionCreate(ic *gateway.InteractionCreateEvent) {
 	}
 }
 
-// ExecuteSlash executes slash commands. Only one layer for now, so no subcommands, sorry :(
+// ExecuteSlash executes slash commands, including subcommands in groups and subgroups.
+// Errors returned by the command are passed to Router.OnError, if it's set, and aren't returned.
 func (r *Router) ExecuteSlash(ctx *SlashContext) (err error) {
-	err = r.executeSlash(true, ctx, r.cmds, &r.cmdMu)
+	err = r.executeSlash(true, ctx, r.cmds, &r.cmdMu, nil)
 	if err == errCommandRun {
 		return nil
 	}
@@ -43,24 +59,47 @@ func errCommand(err error) error {
 	return err
 }
 
-func (r *Router) executeSlash(isTopLevel bool, ctx *SlashContext, cmds map[string]*Command, mu *sync.RWMutex) (err error) {
+func (r *Router) executeSlash(isTopLevel bool, ctx *SlashContext, cmds map[string]*Command, mu *sync.RWMutex, mws []Middleware) (err error) {
+	// user and message commands are stored separately
+	if isTopLevel && ctx.IsContextCommand() {
+		return r.executeContextCommand(ctx)
+	}
+
 	// first, check subcommands
 	if len(ctx.CommandOptions) > 0 && isTopLevel {
-		for _, g := range r.SlashGroups {
+		for _, g := range r.slashGroups() {
 			if strings.EqualFold(g.Name, ctx.CommandName) {
+				// groups limited to other guilds shouldn't be synced there, but check anyway
+				if len(g.Guilds) != 0 && !guildInSlice(ctx.Event.GuildID, g.Guilds) {
+					return errCommand(r.respondCheck(ctx, &UnavailableError{}))
+				}
+
 				nctx := &SlashContext{}
 				*nctx = *ctx
-				nctx.CommandName = ctx.CommandOptions[0].Name
-				nctx.CommandOptions = ctx.CommandOptions[0].Options
-
-				// convert subcommands slice to a map
-				m := map[string]*Command{}
-				for _, cmd := range g.Subcommands {
-					m[strings.ToLower(cmd.Name)] = cmd
+				mws := g.middlewares
+				opt := ctx.CommandOptions[0]
+
+				// if this is a subcommand group, descend one more level
+				if opt.Type == discord.SubcommandGroupOptionType {
+					sub := g.subgroup(opt.Name)
+					if sub == nil || len(opt.Options) == 0 {
+						err = ctx.SendEphemeral(ctx.Translate(MsgBrokenSlashCommand, EscapeBackticks(ctx.CommandName+" "+opt.Name)))
+						return errCommand(err)
+					}
+
+					g = sub
+					mws = append(mws[:len(mws):len(mws)], sub.middlewares...)
+					nctx.CommandPath = append(nctx.CommandPath[:len(nctx.CommandPath):len(nctx.CommandPath)], opt.Name)
+					opt = opt.Options[0]
 				}
+
+				nctx.CommandName = opt.Name
+				nctx.CommandOptions = opt.Options
+				nctx.CommandPath = append(nctx.CommandPath[:len(nctx.CommandPath):len(nctx.CommandPath)], opt.Name)
+
 				var nmu sync.RWMutex // this doesn't matter so we just create a new one
 
-				return r.executeSlash(false, nctx, m, &nmu)
+				return r.executeSlash(false, nctx, g.commandMap(), &nmu, mws)
 			}
 		}
 	}
@@ -68,80 +107,119 @@ func (r *Router) executeSlash(isTopLevel bool, ctx *SlashContext, cmds map[strin
 	// else, we try top-level commands (or skip to this immediately if it isn't the top level)
 	mu.RLock()
 	cmd, ok := cmds[ctx.CommandName]
-	if !ok || cmd.SlashCommand == nil {
+	if !ok || cmd.SlashCommand == nil || !cmd.availableIn(ctx.Event.GuildID) {
 		mu.RUnlock()
-		err = ctx.SendEphemeral(fmt.Sprintf("Looks like you found a command (``%v``) that's registered as a slash command, but doesn't work as one :(\nPlease report this to the bot developer as this is a bug!", EscapeBackticks(ctx.CommandName)))
+		err = ctx.SendEphemeral(ctx.Translate(MsgBrokenSlashCommand, EscapeBackticks(ctx.CommandName)))
 		return errCommand(err)
 	}
 	mu.RUnlock()
 
+	// if the command (or a group it's in) is disabled here, stop
+	if err = r.checkToggles(ctx, ctx.Event.GuildID, ctx.CommandPath, cmd); err != nil {
+		return errCommand(r.respondCheck(ctx, err))
+	}
+
+	return r.execSlashCommand(ctx, cmd, mws)
+}
+
+// execSlashCommand runs a resolved slash command.
+func (r *Router) execSlashCommand(ctx *SlashContext, cmd *Command, mws []Middleware) (err error) {
 	ctx.Command = cmd
 
+	stop := r.startAutoDefer(ctx, cmd)
+	defer stop()
+
+	// run the command wrapped in all applicable middleware, recovering from any panics
+	err = r.safeRun(slashIncident("slash command", ctx), func(msg string) error { return ctx.SendEphemeral(msg) }, func() error {
+		return chain(func(cmd *Command, _ Contexter) error {
+			return r.runSlashCommand(ctx, cmd)
+		}, r.routerMiddleware(), mws, cmd.middlewares)(cmd, ctx)
+	})
+
+	// if a check failed, let the router's responder handle it
+	err = r.respondCheck(ctx, err)
+	// and if the command itself errored, the router's error handler
+	err = r.handleError(ctx, ctx.CommandPath, err)
+	return errCommand(err)
+}
+
+// runSlashCommand runs the built-in checks for a slash command, and then the command itself.
+// Failed checks return a CheckError.
+func (r *Router) runSlashCommand(ctx *SlashContext, cmd *Command) (err error) {
 	if (cmd.GuildOnly || cmd.Permissions != 0) && !ctx.Event.GuildID.IsValid() {
-		err = ctx.SendEphemeral(":x: This command cannot be run in DMs.")
-		return errCommand(err)
+		return &GuildOnlyError{}
 	}
 
 	if r.BlacklistFunc != nil && cmd.Blacklistable {
 		if r.BlacklistFunc(ctx) {
-			err = ctx.SendEphemeral("This command can't be used here.")
-			return errCommand(err)
+			return &BlacklistedError{}
 		}
 	}
 
 	if cmd.GuildPermissions != 0 {
 		if ctx.Guild == nil || ctx.Member == nil {
-			err = ctx.SendEphemeral(":x: This command cannot be used in DMs.")
-			return errCommand(err)
+			return &GuildOnlyError{}
 		}
-		if !ctx.GuildPerms().Has(cmd.GuildPermissions) {
-			err = ctx.SendEphemeral(fmt.Sprintf(":x: You are not allowed to use this command. You are missing the following permissions:\n> ```%v```", strings.Join(PermStrings(cmd.GuildPermissions), ", ")))
-			return errCommand(err)
+		if perms := ctx.GuildPerms(); !perms.Has(cmd.GuildPermissions) {
+			return &PermissionError{Missing: cmd.GuildPermissions &^ perms}
 		}
 	}
 
 	if cmd.Permissions != 0 {
-		if ctx.Member != nil && ctx.Guild != nil {
-			if !discord.CalcOverrides(*ctx.Guild, *ctx.Channel, *ctx.Member, ctx.Guild.Roles).Has(cmd.Permissions) {
-				err = ctx.SendEphemeral(fmt.Sprintf(":x: You are not allowed to use this command. You are missing the following permissions:\n> ```%v```", strings.Join(PermStrings(cmd.Permissions), ", ")))
-				return errCommand(err)
-			}
-		} else {
-			err = ctx.SendEphemeral(":x: This command cannot be run in DMs.")
-			return errCommand(err)
+		if ctx.Member == nil || ctx.Guild == nil {
+			return &GuildOnlyError{}
+		}
+		if perms := discord.CalcOverrides(*ctx.Guild, *ctx.Channel, *ctx.Member, ctx.Guild.Roles); !perms.Has(cmd.Permissions) {
+			return &PermissionError{Missing: cmd.Permissions &^ perms, Channel: true}
 		}
 	}
 
-	if cmd.OwnerOnly {
-		isOwner := false
-		for _, u := range ctx.Router.BotOwners {
-			if ctx.Author.ID.String() == u {
-				isOwner = true
-				break
-			}
+	if cmd.OwnerOnly && !r.isOwner(ctx.Author.ID) {
+		return &OwnerOnlyError{}
+	}
+
+	if cmd.CustomPermissions != nil {
+		b, err := cmd.CustomPermissions.Check(ctx)
+		// if it errored, or returned false, return a permission error
+		if err != nil || !b {
+			return &PermissionError{Custom: cmd.CustomPermissions.String(ctx), Err: err}
 		}
+	}
 
-		if !isOwner {
-			err = ctx.SendEphemeral(":x: This command can only be used by a bot owner.")
-			return errCommand(err)
+	// check for a cooldown, and reserve a use of it
+	if !r.cooldownExempt(ctx, cmd) {
+		remaining, releaseCooldown := r.reserveCooldown(cmd, ctx.CommandPath, ctx.Author.ID, ctx.Event.ChannelID, ctx.Event.GuildID)
+		if remaining > 0 {
+			return &CooldownError{Cooldown: cmd.Cooldown, Uses: cmd.CooldownUses, Scope: cmd.CooldownScope, Remaining: remaining}
 		}
+		// if the command doesn't run successfully, it shouldn't count towards the cooldown
+		defer func() {
+			if err != nil {
+				releaseCooldown()
+			}
+		}()
 	}
 
-	if cmd.CustomPermissions != nil {
-		b, err := cmd.CustomPermissions.Check(ctx)
-		// if it errored, send that error and return
-		if err != nil {
-			err = ctx.SendEphemeral(fmt.Sprintf(":x: An internal error occurred when checking your permissions.\nThe following permission(s) could not be checked:\n> ```%s```", cmd.CustomPermissions.String(ctx)))
-			return errCommand(err)
+	if cmd.Params != nil {
+		if err := ctx.parseParams(); err != nil {
+			e := newArgumentError(MsgInvalidInput, err)
+			e.Err = err
+			return e
 		}
+	}
 
-		// else if it returned false, show that error and return
-		if !b {
-			err = ctx.SendEphemeral(fmt.Sprintf(":x: You are not allowed to use this command. You are missing the following permission(s):\n> ```%s```", cmd.CustomPermissions.String(ctx)))
-			return errCommand(err)
+	// wait for the command to be available, if it has a concurrency limit.
+	// queued interactions are deferred, as they'd otherwise expire while waiting,
+	// and can't wait longer than their token is valid for.
+	release, err := r.acquireConcurrency(cmd, ctx.CommandPath, ctx.Author.ID, ctx.Event.ChannelID, ctx.Event.GuildID, maxSlashQueueWait, func() {
+		if err := ctx.Defer(r.AutoDeferEphemeral); err != nil {
+			r.Logger.Error("deferring queued interaction %v: %v", ctx.InteractionID, err)
 		}
+	})
+	if err != nil {
+		return err
 	}
+	defer release()
 
-	err = cmd.SlashCommand(ctx)
-	return errCommand(err)
+	return cmd.SlashCommand(ctx)
 }
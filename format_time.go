@@ -71,6 +71,12 @@ func pluralize(val int64) string {
 
 // HumanizeDuration ...
 func HumanizeDuration(precision DurationFormatPrecision, in time.Duration) string {
+	return humanizeDuration(precision, in, func(_, def string) string { return def })
+}
+
+// humanizeDuration formats a duration, using tr to translate the units.
+// tr is given a message key and the English default.
+func humanizeDuration(precision DurationFormatPrecision, in time.Duration, tr func(key, def string) string) string {
 	seconds := int64(in.Seconds())
 
 	out := make([]string, 0)
@@ -79,7 +85,8 @@ func HumanizeDuration(precision DurationFormatPrecision, in time.Duration) strin
 		curPrec := DurationFormatPrecision(i)
 		units := curPrec.FromSeconds(seconds)
 		if units > 0 {
-			out = append(out, fmt.Sprintf("%d %s%s", units, curPrec.String(), pluralize(units)))
+			unit := curPrec.String() + pluralize(units)
+			out = append(out, fmt.Sprintf("%d %s", units, tr(msgDurationPrefix+unit, unit)))
 		}
 	}
 
@@ -87,7 +94,7 @@ func HumanizeDuration(precision DurationFormatPrecision, in time.Duration) strin
 
 	for i := len(out) - 1; i >= 0; i-- {
 		if i == 0 && i != len(out)-1 {
-			outStr += " and "
+			outStr += " " + tr(MsgDurationAnd, "and") + " "
 		} else if i != len(out)-1 {
 			outStr += " "
 		}
@@ -95,7 +102,7 @@ func HumanizeDuration(precision DurationFormatPrecision, in time.Duration) strin
 	}
 
 	if outStr == "" {
-		outStr = "less than 1 " + precision.String()
+		outStr = fmt.Sprintf(tr(MsgDurationLessThan, "less than 1 %v"), tr(msgDurationPrefix+precision.String(), precision.String()))
 	}
 
 	return outStr
@@ -4,7 +4,6 @@ go 1.15
 
 require (
 	emperror.dev/errors v0.8.0
-	github.com/ReneKroon/ttlcache/v2 v2.1.0
 	github.com/diamondburned/arikawa/v3 v3.4.0
 	github.com/spf13/pflag v1.0.5
 	github.com/starshine-sys/snowflake/v2 v2.0.0
@@ -1,7 +1,9 @@
 package bcr
 
 import (
+	"sort"
 	"strings"
+	"sync/atomic"
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/discord"
@@ -10,36 +12,104 @@ import (
 // Group is used for creating slash subcommands.
 // No, we can't use the normal system,
 // because a command with subcommands can't *itself* be invoked as a command.
-// Also, no subcommand groups because those make everything more complicated
-// and shouldn't be needed at this scale. Might change in the future, who knows!
+//
+// A top-level group can also contain subgroups (Discord's subcommand groups), for commands like `/config roles add`.
+// Subgroups can't contain further subgroups, as Discord only allows two levels of nesting.
 type Group struct {
 	Name        string
 	Description string
 	Subcommands []*Command
+	Subgroups   []*Group
+
+	// NameLocalizations and DescriptionLocalizations are the group's name and description in other languages.
+	NameLocalizations        discord.StringLocales
+	DescriptionLocalizations discord.StringLocales
+
+	// middlewares are run for every subcommand in the group, see Group.Use.
+	middlewares []Middleware
+
+	// Guilds limits the group to the given guilds, see Command.Guilds.
+	// Only used for top-level groups.
+	Guilds []discord.GuildID
 }
 
 // Add adds a subcommand to the group.
 func (g *Group) Add(cmd *Command) *Group {
+	cmd.checkParams()
 	g.Subcommands = append(g.Subcommands, cmd)
 	return g
 }
 
+// AddSubgroup adds a subgroup to the group. Will panic if the subgroup itself has subgroups!
+func (g *Group) AddSubgroup(sub *Group) *Group {
+	if len(sub.Subgroups) != 0 {
+		panic("subgroup " + sub.Name + " has subgroups, but Discord only allows two levels of nesting")
+	}
+
+	g.Subgroups = append(g.Subgroups, sub)
+	return g
+}
+
+// subgroup returns the subgroup with the given name, or nil if it doesn't exist.
+func (g *Group) subgroup(name string) *Group {
+	for _, sub := range g.Subgroups {
+		if strings.EqualFold(sub.Name, name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// commandMap returns the group's subcommands as a map.
+func (g *Group) commandMap() map[string]*Command {
+	m := map[string]*Command{}
+	for _, cmd := range g.Subcommands {
+		m[strings.ToLower(cmd.Name)] = cmd
+	}
+	return m
+}
+
 // Command returns the group as a discord.Command.
 func (g Group) Command() api.CreateCommandData {
+	perms, noDM := g.slashPermissions()
+
 	c := api.CreateCommandData{
-		Type:        discord.ChatInputCommand,
-		Name:        strings.ToLower(g.Name),
-		Description: g.Description,
+		Type:                     discord.ChatInputCommand,
+		Name:                     strings.ToLower(g.Name),
+		Description:              g.Description,
+		NameLocalizations:        g.NameLocalizations,
+		DescriptionLocalizations: g.DescriptionLocalizations,
+		DefaultMemberPermissions: perms,
+		NoDMPermission:           noDM,
 	}
 
+	for _, o := range g.subcommandOptions() {
+		c.Options = append(c.Options, o)
+	}
+
+	for _, sub := range g.Subgroups {
+		c.Options = append(c.Options, &discord.SubcommandGroupOption{
+			OptionName:               strings.ToLower(sub.Name),
+			Description:              sub.Description,
+			OptionNameLocalizations:  sub.NameLocalizations,
+			DescriptionLocalizations: sub.DescriptionLocalizations,
+			Subcommands:              sub.subcommandOptions(),
+		})
+	}
+
+	return c
+}
+
+// subcommandOptions returns the group's subcommands as slash command options.
+func (g Group) subcommandOptions() (subcommands []*discord.SubcommandOption) {
 	for _, cmd := range g.Subcommands {
 		if cmd.SlashCommand == nil {
 			continue
 		}
 
 		options := []discord.CommandOptionValue(nil)
-		if cmd.Options != nil {
-			for _, o := range *cmd.Options {
+		if opts, ok := cmd.slashOptions(); ok {
+			for _, o := range opts {
 				v, ok := o.(discord.CommandOptionValue)
 				if ok {
 					options = append(options, v)
@@ -47,25 +117,134 @@ func (g Group) Command() api.CreateCommandData {
 			}
 		}
 
-		c.Options = append(c.Options, &discord.SubcommandOption{
-			OptionName:  strings.ToLower(cmd.Name),
-			Description: cmd.Summary,
-			Options:     options,
+		subcommands = append(subcommands, &discord.SubcommandOption{
+			OptionName:               strings.ToLower(cmd.Name),
+			Description:              cmd.Summary,
+			OptionNameLocalizations:  cmd.NameLocalizations,
+			DescriptionLocalizations: cmd.DescriptionLocalizations,
+			Options:                  options,
 		})
 	}
 
-	return c
+	return subcommands
 }
 
-// AddGroup adds a slash command group. Will panic if the group's name already exists as a slash command!
+// AddGroup adds a slash command group.
+// Will panic if the group's name already exists as a slash command, or as another group (including groups derived from commands)!
 func (r *Router) AddGroup(g *Group) {
 	r.cmdMu.RLock()
-	defer r.cmdMu.RUnlock()
 	for _, cmd := range r.cmds {
-		if strings.EqualFold(cmd.Name, g.Name) && cmd.Options != nil && cmd.SlashCommand != nil {
+		if _, ok := cmd.slashOptions(); ok && strings.EqualFold(cmd.Name, g.Name) && cmd.SlashCommand != nil {
+			r.cmdMu.RUnlock()
 			panic("slash command with name " + g.Name + " already exists!")
 		}
 	}
+	r.cmdMu.RUnlock()
+
+	for _, existing := range r.slashGroups() {
+		if strings.EqualFold(existing.Name, g.Name) {
+			panic("slash command group with name " + g.Name + " already exists!")
+		}
+	}
 
 	r.SlashGroups = append(r.SlashGroups, g)
+	r.invalidateSlashGroups()
+}
+
+// slashGroup converts a command with subcommands into a slash command group.
+// Subcommands with their own slash subcommands become subgroups (if nested is true), other subcommands are added if SlashCommand is set.
+// Returns false if none of the command's subcommands are slash commands.
+func (c *Command) slashGroup(nested bool) (*Group, bool) {
+	c.subMu.RLock()
+	subCmds := c.Subcommands()
+	c.subMu.RUnlock()
+
+	if len(subCmds) == 0 {
+		return nil, false
+	}
+
+	g := &Group{
+		Name:        c.Name,
+		Description: c.Summary,
+		middlewares: c.middlewares,
+
+		NameLocalizations:        c.NameLocalizations,
+		DescriptionLocalizations: c.DescriptionLocalizations,
+		Guilds:                   c.Guilds,
+	}
+
+	for _, sub := range subCmds {
+		if nested {
+			if sg, ok := sub.slashGroup(false); ok {
+				g.Subgroups = append(g.Subgroups, sg)
+				continue
+			}
+		}
+
+		if sub.SlashCommand != nil {
+			g.Subcommands = append(g.Subcommands, sub)
+		}
+	}
+
+	if len(g.Subcommands) == 0 && len(g.Subgroups) == 0 {
+		return nil, false
+	}
+
+	sort.Sort(Commands(g.Subcommands))
+	return g, true
+}
+
+// slashGroups returns all slash command groups: those added with AddGroup,
+// and those derived from commands with slash subcommands.
+// The groups are built once, and rebuilt after commands, subcommands, or groups are added and when commands are synced.
+func (r *Router) slashGroups() []*Group {
+	r.groupMu.Lock()
+	defer r.groupMu.Unlock()
+
+	// load this before building, so subcommands added while building cause another rebuild
+	gen := atomic.LoadUint64(&subcommandGen)
+	if !r.groupsBuilt || r.groupsGen != gen {
+		r.groups = r.buildSlashGroups()
+		r.groupsBuilt = true
+		r.groupsGen = gen
+	}
+	return r.groups
+}
+
+// invalidateSlashGroups makes the next call to slashGroups rebuild the groups.
+func (r *Router) invalidateSlashGroups() {
+	r.groupMu.Lock()
+	r.groupsBuilt = false
+	r.groupMu.Unlock()
+}
+
+func (r *Router) buildSlashGroups() []*Group {
+	groups := append([]*Group(nil), r.SlashGroups...)
+
+	r.cmdMu.RLock()
+	cmds := r.Commands()
+	r.cmdMu.RUnlock()
+	sort.Sort(Commands(cmds))
+
+outer:
+	for _, cmd := range cmds {
+		// aliases share their target's subcommands, so they'd be duplicate groups
+		if cmd.aliasOf != nil {
+			continue
+		}
+
+		g, ok := cmd.slashGroup(true)
+		if !ok {
+			continue
+		}
+
+		for _, existing := range groups {
+			if strings.EqualFold(existing.Name, g.Name) {
+				r.Logger.Error("not adding slash command group for command %v: a group with that name already exists", cmd.Name)
+				continue outer
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups
 }
@@ -32,30 +32,38 @@ func (ctx *Context) tryHelp() error {
 func (ctx *Context) Help(path []string) (err error) {
 	// recurse into subcommands
 	cmds := ctx.Router.cmds
+	mu := &ctx.Router.cmdMu
 	var cmd *Command
 	for i, n := range path {
 		var ok bool
 
 		// we tried recursing, but the map is nil, so the command wasn't found
 		if cmds == nil {
-			_, err = ctx.Send(fmt.Sprintf(":x: Command ``%v`` not found.", EscapeBackticks(strings.Join(path, " "))))
+			_, err = ctx.Send(ctx.Translate(MsgHelpNotFound, EscapeBackticks(strings.Join(path, " "))))
 			return err
 		}
 
-		// the command name wasn't found
+		// the command name wasn't found, so suggest a similar one if there is one
 		if cmd, ok = cmds[n]; !ok {
-			_, err = ctx.Send(fmt.Sprintf(":x: Command ``%v`` not found.", EscapeBackticks(strings.Join(path, " "))))
+			msg := ctx.Translate(MsgHelpNotFound, EscapeBackticks(strings.Join(path, " ")))
+			if s := suggestCommand(n, cmds, mu, ctx.Message.GuildID); s != nil {
+				suggestion := append(path[:i:i], strings.ToLower(s.Name))
+				msg += "\n" + ctx.Translate(MsgHelpSuggestion, EscapeBackticks(strings.Join(suggestion, " ")))
+			}
+
+			_, err = ctx.Send(msg)
 			return err
 		}
 
 		// we've not reached the end of the loop, so try recursing
 		if i != len(path)-1 {
 			cmds = cmd.subCmds
+			mu = &cmd.subMu
 		}
 	}
 
 	if cmd == nil {
-		_, err = ctx.Send(fmt.Sprintf(":x: Command ``%v`` not found.", EscapeBackticks(strings.Join(path, " "))))
+		_, err = ctx.Send(ctx.Translate(MsgHelpNotFound, EscapeBackticks(strings.Join(path, " "))))
 		return err
 	}
 
@@ -68,7 +76,7 @@ func (ctx *Context) Help(path []string) (err error) {
 
 	if cmd.Description != "" {
 		fields = append(fields, discord.EmbedField{
-			Name:  "Description",
+			Name:  ctx.Translate(MsgHelpDescription),
 			Value: cmd.Description,
 		})
 	}
@@ -102,21 +110,41 @@ func (ctx *Context) Help(path []string) (err error) {
 			flagDesc += fmt.Sprintf("`-%v, --%v`: %v\n", f.Shorthand, f.Name, f.Usage)
 		})
 
-		flagDesc += "\n\nSquare brackets (`[]`) denote that an argument is **optional**.\nTo input an argument with spaces, wrap it in quotes (`\"\"`); to add quotes, escape them with a backslash (`\\`)."
+		flagDesc += "\n\n" + ctx.Translate(MsgHelpFlagNote)
 	}
 
-	if cmd.Usage != "" {
-		usage += " " + cmd.Usage
+	if u := cmd.ParamUsage(); u != "" {
+		usage += " " + u
 	}
 
 	fields = append(fields, discord.EmbedField{
-		Name:  "Usage",
+		Name:  ctx.Translate(MsgHelpUsage),
 		Value: "`" + strings.TrimSpace(usage) + "`",
 	})
 
+	if len(cmd.Params) != 0 {
+		var b strings.Builder
+		for _, p := range cmd.Params {
+			b.WriteString(fmt.Sprintf("`%v` (%v", p.Name, ctx.Router.ParamTypeString(ctx.Locale(), p.Type)))
+			if !p.Required {
+				b.WriteString(", " + ctx.Translate(MsgHelpOptional))
+			}
+			b.WriteString(")")
+			if p.Description != "" {
+				b.WriteString(": " + p.Description)
+			}
+			b.WriteString("\n")
+		}
+
+		fields = append(fields, discord.EmbedField{
+			Name:  ctx.Translate(MsgHelpArguments),
+			Value: b.String(),
+		})
+	}
+
 	if flagDesc != "" {
 		fields = append(fields, discord.EmbedField{
-			Name:  "Flags",
+			Name:  ctx.Translate(MsgHelpFlags),
 			Value: flagDesc,
 		})
 	}
@@ -125,11 +153,11 @@ func (ctx *Context) Help(path []string) (err error) {
 		s := []string{}
 
 		if cmd.GuildPermissions != 0 {
-			s = append(s, "**Server:** "+strings.Join(PermStrings(cmd.GuildPermissions), ", "))
+			s = append(s, ctx.Translate(MsgHelpServerPerms, ctx.permStrings(cmd.GuildPermissions)))
 		}
 
 		if cmd.Permissions != 0 {
-			s = append(s, "**Channel:** "+strings.Join(PermStrings(cmd.Permissions), ", "))
+			s = append(s, ctx.Translate(MsgHelpChannelPerms, ctx.permStrings(cmd.Permissions)))
 		}
 
 		if cmd.CustomPermissions != nil {
@@ -142,14 +170,14 @@ func (ctx *Context) Help(path []string) (err error) {
 		}
 
 		fields = append(fields, discord.EmbedField{
-			Name:  "Required permissions",
+			Name:  ctx.Translate(MsgHelpPermissions),
 			Value: strings.Join(s, "\n"),
 		})
 	}
 
 	if len(cmd.Aliases) != 0 {
 		fields = append(fields, discord.EmbedField{
-			Name:  "Aliases",
+			Name:  ctx.Translate(MsgHelpAliases),
 			Value: fmt.Sprintf("`%v`", strings.Join(cmd.Aliases, ", ")),
 		})
 	}
@@ -181,14 +209,14 @@ func (ctx *Context) Help(path []string) (err error) {
 			}
 		}
 		fields = append(fields, discord.EmbedField{
-			Name:  "Subcommand(s)",
+			Name:  ctx.Translate(MsgHelpSubcommands),
 			Value: b.String(),
 		})
 	}
 
 	_, err = ctx.Send("", discord.Embed{
 		Title:       "`" + strings.ToUpper(strings.Join(title, " ")) + "`",
-		Description: DefaultValue(cmd.Summary, "No summary provided"),
+		Description: DefaultValue(cmd.Summary, ctx.Translate(MsgHelpNoSummary)),
 		Fields:      fields,
 		Color:       ctx.Router.EmbedColor,
 	})
@@ -0,0 +1,282 @@
+package bcr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// Translator translates the router's user-facing messages.
+type Translator interface {
+	// Translate returns the message with the given key in the given language.
+	// If the message isn't translated, it should return false, in which case the English message is used.
+	//
+	// Messages are passed to fmt.Sprintf, so use explicit argument indexes (e.g. %[2]v) if a translation changes the order of arguments.
+	Translate(lang discord.Language, key string) (msg string, ok bool)
+}
+
+// Translations is a Translator backed by a map of languages to message keys and messages.
+type Translations map[discord.Language]map[string]string
+
+var _ Translator = Translations(nil)
+
+// Translate implements Translator.
+func (t Translations) Translate(lang discord.Language, key string) (string, bool) {
+	msg, ok := t[lang][key]
+	return msg, ok
+}
+
+// Message keys used by the router.
+// Permission names use "perm." followed by their name in English (e.g. "perm.Manage Server"),
+// duration units use "duration." followed by the unit (e.g. "duration.second" and "duration.seconds"),
+// and parameter types use "param_type." followed by their name in English (e.g. "param_type.integer").
+const (
+	MsgGuildOnly            = "guild_only"
+	MsgOwnerOnly            = "owner_only"
+	MsgCantUseHere          = "cant_use_here"
+	MsgMissingPerms         = "missing_perms"
+	MsgCustomPermsError     = "custom_perms_error"
+	MsgMissingCustomPerms   = "missing_custom_perms"
+	MsgCooldown             = "cooldown"
+	MsgConcurrencyLimit     = "concurrency_limit"
+	MsgConcurrencyTimeout   = "concurrency_timeout"
+	MsgSlowDown             = "slow_down"
+	MsgCommandDisabled      = "command_disabled"
+	MsgSuggestion           = "suggestion"
+	MsgSuggestionButton     = "suggestion_button"
+	MsgFlagError            = "flag_error"
+	MsgNotEnoughArgs        = "not_enough_args"
+	MsgTooManyArgs          = "too_many_args"
+	MsgExactArgs            = "exact_args"
+	MsgMissingArg           = "missing_arg"
+	MsgInvalidArg           = "invalid_arg"
+	MsgInvalidInput         = "invalid_input"
+	MsgBrokenSlashCommand   = "broken_slash_command"
+	MsgBrokenContextCommand = "broken_context_command"
+	MsgNoEmbedPerms         = "no_embed_perms"
+	MsgCommandError         = "command_error"
+	MsgPanic                = "panic"
+
+	MsgHelpNotFound     = "help.not_found"
+	MsgHelpSuggestion   = "help.suggestion"
+	MsgHelpNoSummary    = "help.no_summary"
+	MsgHelpDescription  = "help.description"
+	MsgHelpUsage        = "help.usage"
+	MsgHelpArguments    = "help.arguments"
+	MsgHelpOptional     = "help.optional"
+	MsgHelpFlags        = "help.flags"
+	MsgHelpFlagNote     = "help.flag_note"
+	MsgHelpPermissions  = "help.permissions"
+	MsgHelpServerPerms  = "help.server_perms"
+	MsgHelpChannelPerms = "help.channel_perms"
+	MsgHelpAliases      = "help.aliases"
+	MsgHelpSubcommands  = "help.subcommands"
+
+	MsgToggleDisabled       = "toggle.disabled"
+	MsgToggleEnabled        = "toggle.enabled"
+	MsgToggleNotDisabled    = "toggle.not_disabled"
+	MsgToggleUnknown        = "toggle.unknown"
+	MsgToggleCantDisable    = "toggle.cant_disable"
+	MsgToggleChannelAndRole = "toggle.channel_and_role"
+	MsgToggleNone           = "toggle.none"
+	MsgToggleListTitle      = "toggle.list_title"
+	MsgToggleCommand        = "toggle.command"
+	MsgToggleModule         = "toggle.module"
+	MsgToggleInGuild        = "toggle.in_guild"
+	MsgToggleInChannel      = "toggle.in_channel"
+	MsgToggleForRole        = "toggle.for_role"
+
+	MsgPrefixAdded     = "prefix.added"
+	MsgPrefixRemoved   = "prefix.removed"
+	MsgPrefixReset     = "prefix.reset"
+	MsgPrefixEmpty     = "prefix.empty"
+	MsgPrefixExists    = "prefix.exists"
+	MsgPrefixNotFound  = "prefix.not_found"
+	MsgPrefixTooMany   = "prefix.too_many"
+	MsgPrefixTooLong   = "prefix.too_long"
+	MsgPrefixLast      = "prefix.last"
+	MsgPrefixListTitle = "prefix.list_title"
+	MsgPrefixMention   = "prefix.mention"
+
+	MsgDurationAnd      = "duration.and"
+	MsgDurationLessThan = "duration.less_than"
+	MsgDurationAgo      = "duration.ago"
+	MsgDurationIn       = "duration.in"
+)
+
+const (
+	msgPermPrefix       = "perm."
+	msgDurationPrefix   = "duration."
+	msgParamTypePrefix  = "param_type."
+	usageSuffix         = "\n> **Usage:**\n> ```%v%v %v```"
+	brokenCommandSuffix = "\nPlease report this to the bot developer as this is a bug!"
+)
+
+// DefaultMessages are the router's English messages.
+var DefaultMessages = map[string]string{
+	MsgGuildOnly:            ":x: This command cannot be run in DMs.",
+	MsgOwnerOnly:            ":x: This command can only be used by a bot owner.",
+	MsgCantUseHere:          "This command can't be used here.",
+	MsgMissingPerms:         ":x: You are not allowed to use this command. You are missing the following permissions:\n> ```%v```",
+	MsgCustomPermsError:     ":x: An internal error occurred when checking your permissions.\nThe following permission(s) could not be checked:\n> ```%v```",
+	MsgMissingCustomPerms:   ":x: You are not allowed to use this command. You are missing the following permission(s):\n> ```%v```",
+	MsgCooldown:             ":x: This command is on cooldown, try again in %v.",
+	MsgConcurrencyLimit:     ":x: This command is already being used too many times at once, try again later.",
+	MsgConcurrencyTimeout:   ":x: This command is busy, and your request timed out while waiting. Try again later.",
+	MsgSlowDown:             ":x: You're using commands too quickly! Slow down, and try again in %v.",
+	MsgCommandDisabled:      ":x: This command is disabled here.",
+	MsgSuggestion:           ":x: Unknown command ``%v``. Did you mean ``%v``?",
+	MsgSuggestionButton:     "Run %v",
+	MsgFlagError:            ":x: There was an error parsing your input. Try checking this command's help.",
+	MsgNotEnoughArgs:        ":x: You didn't give enough arguments: this command requires %v arguments, but you gave %v." + usageSuffix,
+	MsgTooManyArgs:          ":x: You gave too many arguments: this command requires at most %v arguments, but you gave %v." + usageSuffix,
+	MsgExactArgs:            ":x: This command requires exactly %v arguments, but you gave %v." + usageSuffix,
+	MsgMissingArg:           ":x: You didn't give enough arguments: missing required argument `%v`." + usageSuffix,
+	MsgInvalidArg:           ":x: Couldn't parse ``%v`` as a %v for argument `%v`." + usageSuffix,
+	MsgInvalidInput:         ":x: There was an error parsing your input:\n> ```%v```",
+	MsgBrokenSlashCommand:   "Looks like you found a command (``%v``) that's registered as a slash command, but doesn't work as one :(" + brokenCommandSuffix,
+	MsgBrokenContextCommand: "Looks like you found a command (``%v``) that's registered, but doesn't work :(" + brokenCommandSuffix,
+	MsgNoEmbedPerms:         ":x: I do not have permission to send embeds in this channel. Please ensure I have the `Embed Links` permission here.",
+	MsgCommandError:         ":x: An internal error occurred while running this command.",
+	MsgPanic:                ":x: An internal error occurred. If you report this to the bot developer, please include the incident ID ``%v``.",
+
+	MsgHelpNotFound:     ":x: Command ``%v`` not found.",
+	MsgHelpSuggestion:   "Did you mean ``%v``?",
+	MsgHelpNoSummary:    "No summary provided",
+	MsgHelpDescription:  "Description",
+	MsgHelpUsage:        "Usage",
+	MsgHelpArguments:    "Arguments",
+	MsgHelpOptional:     "optional",
+	MsgHelpFlags:        "Flags",
+	MsgHelpFlagNote:     "Square brackets (`[]`) denote that an argument is **optional**.\nTo input an argument with spaces, wrap it in quotes (`\"\"`); to add quotes, escape them with a backslash (`\\`).",
+	MsgHelpPermissions:  "Required permissions",
+	MsgHelpServerPerms:  "**Server:** %v",
+	MsgHelpChannelPerms: "**Channel:** %v",
+	MsgHelpAliases:      "Aliases",
+	MsgHelpSubcommands:  "Subcommand(s)",
+
+	MsgToggleDisabled:       "Disabled %v %v.",
+	MsgToggleEnabled:        "Enabled %v %v.",
+	MsgToggleNotDisabled:    ":x: %v isn't disabled %v.",
+	MsgToggleUnknown:        ":x: There's no command or module named ``%v``.",
+	MsgToggleCantDisable:    ":x: %v can't be disabled.",
+	MsgToggleChannelAndRole: ":x: You can give a channel or a role, but not both.",
+	MsgToggleNone:           "No commands are disabled in this server.",
+	MsgToggleListTitle:      "Disabled commands",
+	MsgToggleCommand:        "``%v``",
+	MsgToggleModule:         "the **%v** module",
+	MsgToggleInGuild:        "in this server",
+	MsgToggleInChannel:      "in %v",
+	MsgToggleForRole:        "for members with %v",
+
+	MsgPrefixAdded:      "Added ``%v`` as a prefix.",
+	MsgPrefixRemoved:    "Removed ``%v`` from this server's prefixes.",
+	MsgPrefixReset:      "Reset this server's prefixes to the defaults.",
+	MsgPrefixEmpty:      ":x: Prefixes can't be empty.",
+	MsgPrefixExists:     ":x: ``%v`` is already a prefix.",
+	MsgPrefixNotFound:   ":x: ``%v`` isn't one of this server's prefixes.",
+	MsgPrefixTooMany:    ":x: This server already has the maximum of %v prefixes.",
+	MsgPrefixTooLong:    ":x: Prefixes can be at most %v characters long.",
+	MsgPrefixLast:       ":x: ``%v`` is this server's only prefix, so it can't be removed.",
+	MsgPrefixListTitle:  "Prefixes",
+	MsgPrefixMention:    "You can also mention the bot (%v) instead of using a prefix.",
+	MsgDurationAnd:      "and",
+	MsgDurationLessThan: "less than 1 %v",
+	MsgDurationAgo:      "%v ago",
+	MsgDurationIn:       "in %v",
+}
+
+// Translate returns the message with the given key in the given language, formatted with args.
+// If the router has no Translator, or the message isn't translated, the English message from DefaultMessages is used.
+func (r *Router) Translate(lang discord.Language, key string, args ...interface{}) string {
+	msg, ok := "", false
+	if r.Translator != nil && lang != "" {
+		msg, ok = r.Translator.Translate(lang, key)
+	}
+	if !ok {
+		msg, ok = DefaultMessages[key]
+		if !ok {
+			msg = key
+		}
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// translateOr returns the translated message with the given key, or def if it isn't translated.
+func (r *Router) translateOr(lang discord.Language, key, def string) string {
+	if r.Translator != nil && lang != "" {
+		if msg, ok := r.Translator.Translate(lang, key); ok {
+			return msg
+		}
+	}
+	return def
+}
+
+// PermStrings gives translated permission strings for all Discord permissions.
+func (r *Router) PermStrings(lang discord.Language, p discord.Permissions) []string {
+	s := PermStrings(p)
+	for i := range s {
+		s[i] = r.translateOr(lang, msgPermPrefix+s[i], s[i])
+	}
+	return s
+}
+
+// ParamTypeString returns the translated name of a parameter type.
+func (r *Router) ParamTypeString(lang discord.Language, t ParamType) string {
+	return r.translateOr(lang, msgParamTypePrefix+t.String(), t.String())
+}
+
+// HumanizeDuration is a translated version of HumanizeDuration.
+func (r *Router) HumanizeDuration(lang discord.Language, precision DurationFormatPrecision, in time.Duration) string {
+	return humanizeDuration(precision, in, func(key, def string) string {
+		return r.translateOr(lang, key, def)
+	})
+}
+
+// HumanizeTime is a translated version of HumanizeTime.
+func (r *Router) HumanizeTime(lang discord.Language, precision DurationFormatPrecision, in time.Time) string {
+	now := time.Now()
+	if now.After(in) {
+		return r.Translate(lang, MsgDurationAgo, r.HumanizeDuration(lang, precision, now.Sub(in)))
+	}
+	return r.Translate(lang, MsgDurationIn, r.HumanizeDuration(lang, precision, in.Sub(now)))
+}
+
+// Locale returns the language used for this context's messages.
+// This is the result of Router.LocaleFunc, or an empty string if that isn't set.
+func (ctx *Context) Locale() discord.Language {
+	if ctx.Router.LocaleFunc == nil {
+		return ""
+	}
+	return ctx.Router.LocaleFunc(ctx.Message.GuildID, ctx.Author.ID)
+}
+
+// Locale returns the language used for this context's messages.
+// This is the invoking user's locale, falling back to the guild's locale.
+func (ctx *SlashContext) Locale() discord.Language {
+	if ctx.Event.Locale != "" {
+		return ctx.Event.Locale
+	}
+	return discord.Language(ctx.Event.GuildLocale)
+}
+
+// Translate returns the message with the given key in the context's language, see Router.Translate.
+func (ctx *Context) Translate(key string, args ...interface{}) string {
+	return ctx.Router.Translate(ctx.Locale(), key, args...)
+}
+
+// Translate returns the message with the given key in the context's language, see Router.Translate.
+func (ctx *SlashContext) Translate(key string, args ...interface{}) string {
+	return ctx.Router.Translate(ctx.Locale(), key, args...)
+}
+
+// permStrings returns the translated permission strings for p, joined with commas.
+func (ctx *Context) permStrings(p discord.Permissions) string {
+	return strings.Join(ctx.Router.PermStrings(ctx.Locale(), p), ", ")
+}
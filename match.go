@@ -1,10 +1,92 @@
 package bcr
 
 import (
+	"regexp"
+	"strings"
+	"unicode"
+
 	"github.com/diamondburned/arikawa/v3/discord"
 )
 
+// PrefixOptions configures how DefaultPrefixer matches prefixes.
+//
+// Whitespace (including newlines) after a mention prefix is always part of the prefix,
+// so "<@id>  help" and "<@id>\nhelp" both run the help command.
+type PrefixOptions struct {
+	// CaseSensitive makes prefixes case-sensitive. By default, "S!" and "s!" are the same prefix.
+	CaseSensitive bool
+	// Regexes are matched after the normal prefixes. A regex only matches if it matches at the start of the message.
+	// Use the (?i) flag for case-insensitive regexes.
+	Regexes []*regexp.Regexp
+	// NoDMPrefix makes prefixes optional in DMs with the bot.
+	// Messages without a prefix are ignored unless they start with a command's name or alias.
+	NoDMPrefix bool
+}
+
 // MatchPrefix returns true if the message content contains any of the prefixes
 func (r *Router) MatchPrefix(m discord.Message) bool {
-	return r.Prefixer(m) != -1
+	_, _, ok := r.matchPrefix(m)
+	return ok
+}
+
+// matchPrefix splits the message into the prefix used and the rest of the message, using the router's Prefixer.
+// The rest has leading and trailing whitespace trimmed.
+func (r *Router) matchPrefix(m discord.Message) (prefix, rest string, ok bool) {
+	p := r.Prefixer(m)
+	if p < 0 || p > len(m.Content) {
+		return "", "", false
+	}
+
+	return m.Content[:p], strings.TrimSpace(m.Content[p:]), true
+}
+
+// DefaultPrefixer matches the guild's custom prefixes (see GuildPrefixes) or, in DMs and guilds without custom prefixes,
+// Router.Prefixes. The bot's mention prefixes always match. See PrefixOptions for matching options.
+func (r *Router) DefaultPrefixer(m discord.Message) int {
+	opts := r.PrefixOptions
+
+	// mentions first, so the whitespace after them is included
+	for _, p := range r.mentionPrefixes() {
+		if strings.HasPrefix(m.Content, p) {
+			return len(m.Content) - len(strings.TrimLeftFunc(m.Content[len(p):], unicode.IsSpace))
+		}
+	}
+
+	for _, p := range r.GuildPrefixes().Get(m.GuildID) {
+		if n, ok := hasPrefix(m.Content, p, opts.CaseSensitive); ok {
+			return n
+		}
+	}
+
+	for _, re := range opts.Regexes {
+		if loc := re.FindStringIndex(m.Content); loc != nil && loc[0] == 0 {
+			return loc[1]
+		}
+	}
+
+	if opts.NoDMPrefix && !m.GuildID.IsValid() {
+		return 0
+	}
+	return -1
+}
+
+// hasPrefix returns the length of the prefix if s starts with it, optionally ignoring case.
+func hasPrefix(s, prefix string, caseSensitive bool) (int, bool) {
+	if prefix == "" || len(s) < len(prefix) {
+		return 0, false
+	}
+
+	if caseSensitive {
+		return len(prefix), strings.HasPrefix(s, prefix)
+	}
+	return len(prefix), strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// normalizePrefix trims the prefix, and lowercases it if prefixes are case-insensitive.
+func (r *Router) normalizePrefix(prefix string) string {
+	prefix = strings.TrimSpace(prefix)
+	if !r.PrefixOptions.CaseSensitive {
+		prefix = strings.ToLower(prefix)
+	}
+	return prefix
 }
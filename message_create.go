@@ -10,13 +10,18 @@ import (
 // MessageCreate gets called on new messages
 // - makes sure the router has a bot user
 // - checks if the message matches a prefix
+// - checks the router's rate limits
 // - runs commands
 func (r *Router) MessageCreate(m *gateway.MessageCreateEvent) {
 	r.Logger.Debug("received new message (%v) in %v by %v#%v (%v)", m.ID, m.ChannelID, m.Author.Username, m.Author.Discriminator, m.Author.ID)
 
 	// set the bot user if not done already
 	if r.Bot == nil {
-		r.mustSetBotUser(m.GuildID)
+		err := r.SetBotUser(m.GuildID)
+		if err != nil {
+			r.Logger.Error("setting bot user: %v", err)
+			return
+		}
 		r.Prefixes = append(r.Prefixes, fmt.Sprintf("<@%v>", r.Bot.ID), fmt.Sprintf("<@!%v>", r.Bot.ID))
 	}
 
@@ -25,32 +30,40 @@ func (r *Router) MessageCreate(m *gateway.MessageCreateEvent) {
 		return
 	}
 
-	// if the message does not start with any of the bot's prefixes (including mentions), return
-	if !r.MatchPrefix(m.Message) {
-		return
-	}
+	// custom prefixers can panic too, so recover from those
+	// (panics in commands are recovered separately, so the user is shown an error)
+	r.safeRun(newIncident("message create", m.Author.ID, m.ChannelID, m.GuildID), nil, func() error {
+		// if the message does not start with any of the bot's prefixes (including mentions), return
+		if !r.MatchPrefix(m.Message) {
+			return nil
+		}
 
-	// get the context
-	ctx, err := r.NewContext(m)
-	if err != nil {
-		r.Logger.Error("getting context: %v", err)
-		return
-	}
+		// get the context
+		ctx, err := r.NewContext(m)
+		if err != nil {
+			r.Logger.Error("getting context: %v", err)
+			return nil
+		}
 
-	err = r.Execute(ctx)
-	if err != nil {
-		r.Logger.Error("executing command: %v", err)
-		return
-	}
-}
+		// messages without a prefix (in DMs, with PrefixOptions.NoDMPrefix) are most likely normal messages,
+		// so they're only rate limited and executed if they're for an existing command
+		if ctx.Prefix == "" {
+			if c := r.GetCommand(ctx.Command); c == nil || !c.availableIn(m.GuildID) {
+				return nil
+			}
+		}
 
-// mustSetBotUser sets the bot user in the router, panicking if it fails.
-// This is intended to be used in MessageCreate to simplify error handling
-func (r *Router) mustSetBotUser(guildID discord.GuildID) {
-	err := r.SetBotUser(guildID)
-	if err != nil {
-		panic(err)
-	}
+		// if the user is using commands too quickly, ignore the message
+		if r.messageRateLimited(m) {
+			return nil
+		}
+
+		err = r.Execute(ctx)
+		if err != nil {
+			r.Logger.Error("executing command: %v", err)
+		}
+		return nil
+	})
 }
 
 // SetBotUser sets the router's bot user, returning any errors
@@ -35,6 +35,9 @@ func (r *Router) MsgHandlerCreate(e *gateway.MessageCreateEvent) {
 		})
 
 		// run the handler
-		v.fn(v.ctx, e.Message)
+		r.safeRun(commandIncident("message handler", v.ctx), v.ctx.sendFunc(), func() error {
+			v.fn(v.ctx, e.Message)
+			return nil
+		})
 	}
 }
@@ -0,0 +1,61 @@
+package bcr
+
+// HandlerFunc is a function that runs a resolved command.
+// It's what a Middleware wraps.
+type HandlerFunc func(cmd *Command, ctx Contexter) error
+
+// Middleware wraps a HandlerFunc, returning a new HandlerFunc.
+// A middleware can run code before and after calling next, or short-circuit execution by not calling next at all.
+//
+// Middleware is run after the command has been resolved (including subcommands and slash groups),
+// in the following order:
+// router middleware (Router.Use), group middleware (Group.Use, or the parent command's Use for prefix subcommands),
+// command middleware (Command.Use), and finally the built-in checks
+// (guild only, blacklist, owner only, permissions, cooldown, flags, and arguments) followed by the command itself.
+//
+// If a built-in check fails, next returns a CheckError, which is presented to the user by Router.CheckResponder
+// after all middleware has returned.
+//
+// The Contexter passed in is a *Context for prefix commands and a *SlashContext for slash commands.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use adds middleware to the router, which is run for every command.
+func (r *Router) Use(mw ...Middleware) {
+	r.middlewareMu.Lock()
+	r.middlewares = append(r.middlewares, mw...)
+	r.middlewareMu.Unlock()
+}
+
+// Use adds middleware to the command.
+// If the command has subcommands, the middleware is also run for those.
+func (c *Command) Use(mw ...Middleware) *Command {
+	c.middlewares = append(c.middlewares, mw...)
+	return c
+}
+
+// Use adds middleware to the group, which is run for all of its subcommands.
+func (g *Group) Use(mw ...Middleware) *Group {
+	g.middlewares = append(g.middlewares, mw...)
+	return g
+}
+
+// routerMiddleware returns a copy of the router's middleware.
+func (r *Router) routerMiddleware() []Middleware {
+	r.middlewareMu.RLock()
+	defer r.middlewareMu.RUnlock()
+
+	return append([]Middleware(nil), r.middlewares...)
+}
+
+// chain wraps fn in the given middleware, with the first middleware being the outermost.
+func chain(fn HandlerFunc, mws ...[]Middleware) HandlerFunc {
+	all := []Middleware{}
+	for _, m := range mws {
+		all = append(all, m...)
+	}
+
+	for i := len(all) - 1; i >= 0; i-- {
+		fn = all[i](fn)
+	}
+	return fn
+}
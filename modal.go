@@ -0,0 +1,274 @@
+package bcr
+
+import (
+	"context"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/starshine-sys/snowflake/v2"
+)
+
+// Modal is a modal (form) with text inputs.
+type Modal struct {
+	CustomID discord.ComponentID
+	Title    string
+	// Each input is put in its own action row.
+	Inputs []*discord.TextInputComponent
+}
+
+func (m Modal) response() api.InteractionResponse {
+	components := discord.ContainerComponents{}
+	for _, i := range m.Inputs {
+		components = append(components, &discord.ActionRowComponent{i})
+	}
+
+	return api.InteractionResponse{
+		Type: api.ModalResponse,
+		Data: &api.InteractionResponseData{
+			CustomID:   option.NewNullableString(string(m.CustomID)),
+			Title:      option.NewNullableString(m.Title),
+			Components: &components,
+		},
+	}
+}
+
+// RespondModal responds to the given interaction with a modal.
+// The interaction must be a command or component interaction, and must not have been responded to yet.
+func (r *Router) RespondModal(ev *gateway.InteractionCreateEvent, m Modal) error {
+	s, _ := r.StateFromGuildID(ev.GuildID)
+
+	return s.RespondInteraction(ev.ID, ev.Token, m.response())
+}
+
+// SendModal responds to the given interaction (usually from a button handler) with a modal.
+func (ctx *Context) SendModal(ev *gateway.InteractionCreateEvent, m Modal) error {
+	return ctx.State.RespondInteraction(ev.ID, ev.Token, m.response())
+}
+
+// SendModal responds to the slash command with a modal.
+// This must be the first response to the command, and the command can't be deferred.
+func (ctx *SlashContext) SendModal(m Modal) error {
+	err := ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, m.response())
+	if err != nil {
+		return err
+	}
+
+	ctx.markResponded()
+	return nil
+}
+
+// ModalSubmit is a submitted modal.
+type ModalSubmit struct {
+	State  *state.State
+	Router *Router
+
+	// Event is the original raw event
+	Event *gateway.InteractionCreateEvent
+	Data  *discord.ModalInteraction
+}
+
+// Value returns the value of the text input with the given custom ID, or an empty string if it doesn't exist.
+func (m *ModalSubmit) Value(customID discord.ComponentID) string {
+	c, ok := m.Data.Components.Find(customID).(*discord.TextInputComponent)
+	if !ok {
+		return ""
+	}
+	return c.Value
+}
+
+// Values returns the values of all text inputs in the modal.
+func (m *ModalSubmit) Values() map[discord.ComponentID]string {
+	out := map[discord.ComponentID]string{}
+	for _, c := range m.Data.Components {
+		row, ok := c.(*discord.ActionRowComponent)
+		if !ok {
+			continue
+		}
+
+		for _, c := range *row {
+			if i, ok := c.(*discord.TextInputComponent); ok {
+				out[i.CustomID] = i.Value
+			}
+		}
+	}
+	return out
+}
+
+// Respond responds to the modal submission.
+func (m *ModalSubmit) Respond(resp api.InteractionResponse) error {
+	return m.State.RespondInteraction(m.Event.ID, m.Event.Token, resp)
+}
+
+// Reply responds to the modal submission with a message.
+func (m *ModalSubmit) Reply(content string, embeds ...discord.Embed) error {
+	return m.reply(0, content, embeds...)
+}
+
+// ReplyEphemeral responds to the modal submission with an ephemeral message.
+func (m *ModalSubmit) ReplyEphemeral(content string, embeds ...discord.Embed) error {
+	return m.reply(api.EphemeralResponse, content, embeds...)
+}
+
+func (m *ModalSubmit) reply(flags discord.MessageFlags, content string, embeds ...discord.Embed) error {
+	data := api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			AllowedMentions: m.Router.DefaultMentions,
+			Flags:           flags,
+		},
+	}
+
+	if len(embeds) != 0 {
+		data.Data.Embeds = &embeds
+	}
+	if content != "" {
+		data.Data.Content = option.NewNullableString(content)
+	}
+
+	return m.Respond(data)
+}
+
+// Acknowledge acknowledges the modal submission without sending a message.
+// Only valid if the modal was shown in response to a component interaction.
+func (m *ModalSubmit) Acknowledge() error {
+	return m.Respond(api.InteractionResponse{Type: api.DeferredMessageUpdate})
+}
+
+type modalKey struct {
+	user     discord.UserID
+	customID discord.ComponentID
+}
+
+type modalInfo struct {
+	fn func(*ModalSubmit)
+	// id identifies this registration, so removing it doesn't remove a newer handler with the same key
+	id snowflake.Snowflake
+}
+
+// ModalRemoveFunc is returned by AddModalHandler
+type ModalRemoveFunc func()
+
+// AddModalHandler adds a handler for a modal with the given custom ID, submitted by the given user.
+// The handler is removed after it's triggered once, or after the timeout.
+func (ctx *Context) AddModalHandler(
+	user discord.UserID,
+	customID discord.ComponentID,
+	timeout time.Duration,
+	fn func(*Context, *ModalSubmit),
+) ModalRemoveFunc {
+	return ctx.Router.addModalHandler(user, customID, timeout, func(m *ModalSubmit) {
+		fn(ctx, m)
+	})
+}
+
+// AddModalHandler adds a handler for a modal with the given custom ID, submitted by the given user.
+// The handler is removed after it's triggered once, or after the timeout.
+func (ctx *SlashContext) AddModalHandler(
+	user discord.UserID,
+	customID discord.ComponentID,
+	timeout time.Duration,
+	fn func(*SlashContext, *ModalSubmit),
+) ModalRemoveFunc {
+	return ctx.Router.addModalHandler(user, customID, timeout, func(m *ModalSubmit) {
+		fn(ctx, m)
+	})
+}
+
+func (r *Router) addModalHandler(user discord.UserID, customID discord.ComponentID, timeout time.Duration, fn func(*ModalSubmit)) ModalRemoveFunc {
+	key := modalKey{user, customID}
+	id := sGen.Get()
+
+	r.modalMu.Lock()
+	r.modals[key] = modalInfo{fn, id}
+	r.modalMu.Unlock()
+
+	rm := func() {
+		r.modalMu.Lock()
+		if info, ok := r.modals[key]; ok && info.id == id {
+			delete(r.modals, key)
+		}
+		r.modalMu.Unlock()
+	}
+
+	time.AfterFunc(timeout, rm)
+	return rm
+}
+
+// ModalHandler handles modals added by ctx.AddModalHandler
+func (r *Router) ModalHandler(ev *gateway.InteractionCreateEvent) {
+	if ev.Data.InteractionType() != discord.ModalInteractionType {
+		return
+	}
+
+	data, ok := ev.Data.(*discord.ModalInteraction)
+	if !ok {
+		return
+	}
+
+	key := modalKey{ev.SenderID(), data.CustomID}
+
+	r.modalMu.Lock()
+	info, ok := r.modals[key]
+	delete(r.modals, key)
+	r.modalMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	s, _ := r.StateFromGuildID(ev.GuildID)
+	r.safeRun(interactionIncident("modal handler", ev), r.replyInteraction(ev), func() error {
+		info.fn(&ModalSubmit{
+			State:  s,
+			Router: r,
+			Event:  ev,
+			Data:   data,
+		})
+		return nil
+	})
+}
+
+// WaitForModal waits for a modal with the given custom ID to be submitted by the given user.
+func (ctx *Context) WaitForModal(user discord.UserID, customID discord.ComponentID, timeout time.Duration) (m *ModalSubmit, timedOut bool) {
+	return ctx.Router.waitForModal(ctx.State, user, customID, timeout)
+}
+
+// WaitForModal waits for a modal with the given custom ID to be submitted by the given user.
+func (ctx *SlashContext) WaitForModal(user discord.UserID, customID discord.ComponentID, timeout time.Duration) (m *ModalSubmit, timedOut bool) {
+	return ctx.Router.waitForModal(ctx.State, user, customID, timeout)
+}
+
+func (r *Router) waitForModal(s *state.State, user discord.UserID, customID discord.ComponentID, timeout time.Duration) (m *ModalSubmit, timedOut bool) {
+	c, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ev := s.WaitFor(c, func(ev interface{}) bool {
+		v, ok := ev.(*gateway.InteractionCreateEvent)
+		if !ok {
+			return false
+		}
+
+		data, ok := v.Data.(*discord.ModalInteraction)
+		if !ok {
+			return false
+		}
+
+		return data.CustomID == customID && v.SenderID() == user
+	})
+
+	if ev == nil {
+		return nil, true
+	}
+
+	v := ev.(*gateway.InteractionCreateEvent)
+	return &ModalSubmit{
+		State:  s,
+		Router: r,
+		Event:  v,
+		Data:   v.Data.(*discord.ModalInteraction),
+	}, false
+}
@@ -0,0 +1,234 @@
+package bcr
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+// Incident is a panic recovered by the router.
+type Incident struct {
+	// ID is shown to the user, and included in the logs and notifications.
+	ID   string
+	Time time.Time
+
+	// Source is the kind of handler that panicked, such as "command" or "button handler".
+	Source string
+	// Path and Args are the command path and arguments, if the panic happened in a command.
+	Path []string
+	Args []string
+
+	UserID    discord.UserID
+	ChannelID discord.ChannelID
+	GuildID   discord.GuildID
+
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+
+	// lang is the language the user is shown the incident in
+	lang discord.Language
+}
+
+// newIncident returns a new incident for the given source.
+func newIncident(source string, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID) *Incident {
+	return &Incident{
+		Source:    source,
+		UserID:    userID,
+		ChannelID: channelID,
+		GuildID:   guildID,
+	}
+}
+
+// commandIncident returns a new incident for a prefix command.
+func commandIncident(source string, ctx *Context) *Incident {
+	inc := newIncident(source, ctx.Author.ID, ctx.Message.ChannelID, ctx.Message.GuildID)
+	inc.Path = ctx.FullCommandPath
+	inc.Args = ctx.Args
+	inc.lang = ctx.Locale()
+	return inc
+}
+
+// slashIncident returns a new incident for a slash command.
+func slashIncident(source string, ctx *SlashContext) *Incident {
+	inc := newIncident(source, ctx.Author.ID, ctx.Event.ChannelID, ctx.Event.GuildID)
+	inc.Path = ctx.CommandPath
+	inc.lang = ctx.Locale()
+	for _, o := range ctx.CommandOptions {
+		inc.Args = append(inc.Args, o.Name+":"+o.String())
+	}
+	return inc
+}
+
+// interactionIncident returns a new incident for a component or modal handler.
+func interactionIncident(source string, ev *gateway.InteractionCreateEvent) *Incident {
+	inc := newIncident(source, ev.SenderID(), ev.ChannelID, ev.GuildID)
+	inc.lang = ev.Locale
+	return inc
+}
+
+// safeRun runs fn, recovering from any panics.
+// If fn panics, the incident is logged, reported, and shown to the user with reply (if it's not nil),
+// and errCommandRun is returned.
+func (r *Router) safeRun(inc *Incident, reply func(string) error, fn func() error) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		inc.ID = sGen.Get().String()
+		inc.Time = time.Now().UTC()
+		inc.Value = v
+		inc.Stack = debug.Stack()
+
+		r.Logger.Error("recovered panic in %v %v (incident %v): %v\n%s", inc.Source, strings.Join(inc.Path, " "), inc.ID, v, inc.Stack)
+
+		if reply != nil {
+			if err := reply(r.Translate(inc.lang, MsgPanic, inc.ID)); err != nil {
+				r.Logger.Error("sending panic message for incident %v: %v", inc.ID, err)
+			}
+		}
+
+		r.reportIncident(inc)
+		err = errCommandRun
+	}()
+
+	return fn()
+}
+
+// reportIncident sends the incident to Router.OnPanic, Router.IncidentChannel, and the bot owners, as configured.
+func (r *Router) reportIncident(inc *Incident) {
+	if r.OnPanic != nil {
+		r.OnPanic(inc)
+	}
+
+	if !r.IncidentChannel.IsValid() && !r.IncidentDMOwners {
+		return
+	}
+
+	s, _ := r.StateFromGuildID(inc.GuildID)
+	e := inc.Embed()
+
+	if r.IncidentChannel.IsValid() {
+		if _, err := s.SendEmbeds(r.IncidentChannel, e); err != nil {
+			r.Logger.Error("sending incident %v to channel %v: %v", inc.ID, r.IncidentChannel, err)
+		}
+	}
+
+	if r.IncidentDMOwners {
+		for _, owner := range r.BotOwners {
+			sf, err := discord.ParseSnowflake(owner)
+			if err != nil {
+				continue
+			}
+
+			ch, err := s.CreatePrivateChannel(discord.UserID(sf))
+			if err != nil {
+				r.Logger.Error("creating DM channel with owner %v for incident %v: %v", owner, inc.ID, err)
+				continue
+			}
+
+			if _, err = s.SendEmbeds(ch.ID, e); err != nil {
+				r.Logger.Error("sending incident %v to owner %v: %v", inc.ID, owner, err)
+			}
+		}
+	}
+}
+
+// Embed returns an embed describing the incident, for reporting to bot owners.
+func (inc *Incident) Embed() discord.Embed {
+	fields := []discord.EmbedField{{
+		Name:   "Source",
+		Value:  inc.Source,
+		Inline: true,
+	}, {
+		Name:   "User",
+		Value:  fmt.Sprintf("%v (%v)", inc.UserID.Mention(), inc.UserID),
+		Inline: true,
+	}}
+
+	if inc.ChannelID.IsValid() {
+		fields = append(fields, discord.EmbedField{
+			Name:   "Channel",
+			Value:  fmt.Sprintf("%v (%v)", inc.ChannelID.Mention(), inc.ChannelID),
+			Inline: true,
+		})
+	}
+	if inc.GuildID.IsValid() {
+		fields = append(fields, discord.EmbedField{
+			Name:   "Guild",
+			Value:  inc.GuildID.String(),
+			Inline: true,
+		})
+	}
+	if len(inc.Path) != 0 {
+		fields = append(fields, discord.EmbedField{
+			Name:  "Command",
+			Value: "``" + EscapeBackticks(strings.Join(inc.Path, " ")) + "``",
+		})
+	}
+	if len(inc.Args) != 0 {
+		fields = append(fields, discord.EmbedField{
+			Name:  "Arguments",
+			Value: "``" + EscapeBackticks(truncate(strings.Join(inc.Args, " "), 1000)) + "``",
+		})
+	}
+
+	return discord.Embed{
+		Title:       "Incident " + inc.ID,
+		Description: fmt.Sprintf("```%v```\n```%s```", truncate(fmt.Sprint(inc.Value), 500), truncate(string(inc.Stack), 3500)),
+		Fields:      fields,
+		Color:       ColourRed,
+		Timestamp:   discord.NewTimestamp(inc.Time),
+	}
+}
+
+// truncate shortens s to at most n characters, cutting it on a rune boundary.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-3]) + "..."
+}
+
+// replyInteraction returns a function responding to the interaction with an ephemeral message,
+// falling back to a follow-up message if the interaction was already responded to.
+func (r *Router) replyInteraction(ev *gateway.InteractionCreateEvent) func(string) error {
+	return func(msg string) error {
+		s, _ := r.StateFromGuildID(ev.GuildID)
+
+		err := s.RespondInteraction(ev.ID, ev.Token, api.InteractionResponse{
+			Type: api.MessageInteractionWithSource,
+			Data: &api.InteractionResponseData{
+				Content: option.NewNullableString(msg),
+				Flags:   api.EphemeralResponse,
+			},
+		})
+		if err == nil {
+			return nil
+		}
+
+		_, err = s.CreateInteractionFollowup(ev.AppID, ev.Token, api.InteractionResponseData{
+			Content: option.NewNullableString(msg),
+			Flags:   api.EphemeralResponse,
+		})
+		return err
+	}
+}
+
+// sendFunc returns a function sending a message in the context's channel.
+func (ctx *Context) sendFunc() func(string) error {
+	return func(msg string) error {
+		_, err := ctx.Send(msg)
+		return err
+	}
+}
@@ -0,0 +1,479 @@
+package bcr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/bot/extras/shellwords"
+)
+
+// ParamType is the type of a command parameter.
+type ParamType int
+
+// Parameter types
+const (
+	StringParam ParamType = iota
+	IntParam
+	NumberParam
+	BoolParam
+	DurationParam
+	UserParam
+	MemberParam
+	RoleParam
+	ChannelParam
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case StringParam:
+		return "text"
+	case IntParam:
+		return "integer"
+	case NumberParam:
+		return "number"
+	case BoolParam:
+		return "yes/no"
+	case DurationParam:
+		return "duration"
+	case UserParam:
+		return "user"
+	case MemberParam:
+		return "member"
+	case RoleParam:
+		return "role"
+	case ChannelParam:
+		return "channel"
+	}
+	return "unknown"
+}
+
+// Param is a single declarative command parameter.
+// If a command has Params set, these are used to generate its usage string, check and parse its arguments,
+// and (if SlashCommand is set and Options is nil) generate its slash command options.
+//
+// Parsed values can be retrieved by name with the ParamValues methods, through ctx.Params or Contexter.GetParams().
+// The Go types of parsed values are, respectively:
+// string, int64, float64, bool, time.Duration, *discord.User, *discord.Member, *discord.Role, and *discord.Channel.
+type Param struct {
+	Name        string
+	Description string
+	Type        ParamType
+
+	// NameLocalizations and DescriptionLocalizations are the slash command option's name and description in other languages.
+	NameLocalizations        discord.StringLocales
+	DescriptionLocalizations discord.StringLocales
+
+	// Required parameters must come before optional ones.
+	Required bool
+	// Default is used if the parameter isn't given. It must be of the parameter's Go type (see Param).
+	Default interface{}
+	// Rest makes the parameter consume the rest of the input.
+	// Only valid for the last parameter, and only for prefix commands: slash commands receive the option as-is.
+	Rest bool
+}
+
+// ParamValues are parsed parameter values, keyed by name.
+type ParamValues map[string]interface{}
+
+// Has returns true if the named parameter was given or has a default value.
+func (p ParamValues) Has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+// String returns the named parameter as a string, or an empty string if it wasn't given.
+func (p ParamValues) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+// Int returns the named parameter as an int64, or 0 if it wasn't given.
+func (p ParamValues) Int(name string) int64 {
+	v, _ := p[name].(int64)
+	return v
+}
+
+// Number returns the named parameter as a float64, or 0 if it wasn't given.
+func (p ParamValues) Number(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+// Bool returns the named parameter as a bool, or false if it wasn't given.
+func (p ParamValues) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+// Duration returns the named parameter as a time.Duration, or 0 if it wasn't given.
+func (p ParamValues) Duration(name string) time.Duration {
+	v, _ := p[name].(time.Duration)
+	return v
+}
+
+// User returns the named parameter as a user, or nil if it wasn't given.
+// If the parameter is a member, returns the member's user.
+func (p ParamValues) User(name string) *discord.User {
+	switch v := p[name].(type) {
+	case *discord.User:
+		return v
+	case *discord.Member:
+		return &v.User
+	}
+	return nil
+}
+
+// Member returns the named parameter as a member, or nil if it wasn't given.
+func (p ParamValues) Member(name string) *discord.Member {
+	v, _ := p[name].(*discord.Member)
+	return v
+}
+
+// Role returns the named parameter as a role, or nil if it wasn't given.
+func (p ParamValues) Role(name string) *discord.Role {
+	v, _ := p[name].(*discord.Role)
+	return v
+}
+
+// Channel returns the named parameter as a channel, or nil if it wasn't given.
+func (p ParamValues) Channel(name string) *discord.Channel {
+	v, _ := p[name].(*discord.Channel)
+	return v
+}
+
+// GetParams returns the context's parsed parameters.
+func (ctx *Context) GetParams() ParamValues { return ctx.Params }
+
+// GetParams returns the context's parsed parameters.
+func (ctx *SlashContext) GetParams() ParamValues { return ctx.Params }
+
+// ParamUsage returns the usage string for the command.
+// If Usage is set, that is returned; otherwise, it's generated from the command's Params.
+func (c *Command) ParamUsage() string {
+	if c.Usage != "" || len(c.Params) == 0 {
+		return c.Usage
+	}
+
+	s := make([]string, 0, len(c.Params))
+	for _, p := range c.Params {
+		name := p.Name
+		if p.Rest {
+			name += "..."
+		}
+
+		if p.Required {
+			s = append(s, "<"+name+">")
+		} else {
+			s = append(s, "["+name+"]")
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// paramOptions returns the slash command options generated from the command's Params.
+func (c *Command) paramOptions() discord.CommandOptions {
+	opts := discord.CommandOptions{}
+
+	for _, p := range c.Params {
+		name := strings.ToLower(p.Name)
+		desc := DefaultValue(p.Description, p.Name)
+		autocomplete := c.autocompleteFunc(p.Name) != nil
+
+		switch p.Type {
+		case IntParam:
+			opts = append(opts, &discord.IntegerOption{
+				OptionName: name, Description: desc, Required: p.Required, Autocomplete: autocomplete,
+				OptionNameLocalizations: p.NameLocalizations, DescriptionLocalizations: p.DescriptionLocalizations,
+			})
+		case NumberParam:
+			opts = append(opts, &discord.NumberOption{
+				OptionName: name, Description: desc, Required: p.Required, Autocomplete: autocomplete,
+				OptionNameLocalizations: p.NameLocalizations, DescriptionLocalizations: p.DescriptionLocalizations,
+			})
+		case BoolParam:
+			opts = append(opts, &discord.BooleanOption{
+				OptionName: name, Description: desc, Required: p.Required,
+				OptionNameLocalizations: p.NameLocalizations, DescriptionLocalizations: p.DescriptionLocalizations,
+			})
+		case UserParam, MemberParam:
+			opts = append(opts, &discord.UserOption{
+				OptionName: name, Description: desc, Required: p.Required,
+				OptionNameLocalizations: p.NameLocalizations, DescriptionLocalizations: p.DescriptionLocalizations,
+			})
+		case RoleParam:
+			opts = append(opts, &discord.RoleOption{
+				OptionName: name, Description: desc, Required: p.Required,
+				OptionNameLocalizations: p.NameLocalizations, DescriptionLocalizations: p.DescriptionLocalizations,
+			})
+		case ChannelParam:
+			opts = append(opts, &discord.ChannelOption{
+				OptionName: name, Description: desc, Required: p.Required,
+				OptionNameLocalizations: p.NameLocalizations, DescriptionLocalizations: p.DescriptionLocalizations,
+			})
+		default:
+			opts = append(opts, &discord.StringOption{
+				OptionName: name, Description: desc, Required: p.Required, Autocomplete: autocomplete,
+				OptionNameLocalizations: p.NameLocalizations, DescriptionLocalizations: p.DescriptionLocalizations,
+			})
+		}
+	}
+
+	return opts
+}
+
+// slashOptions returns the command's slash command options, and whether it should be registered as a slash command at all.
+func (c *Command) slashOptions() (discord.CommandOptions, bool) {
+	if c.Options != nil {
+		return *c.Options, true
+	}
+
+	if c.Params != nil && c.SlashCommand != nil {
+		return c.paramOptions(), true
+	}
+	return nil, false
+}
+
+// parseParams parses the context's arguments into ctx.Params.
+// If the arguments are invalid, it returns an *ArgumentError.
+func (ctx *Context) parseParams() error {
+	ctx.Params = ParamValues{}
+	params := ctx.Cmd.Params
+
+	for i, p := range params {
+		if i >= len(ctx.Args) {
+			if p.Required {
+				e := newArgumentError(
+					MsgMissingArg,
+					p.Name,
+					ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.ParamUsage(),
+				)
+				e.Param = p.Name
+				return e
+			}
+
+			if p.Default != nil {
+				ctx.Params[p.Name] = p.Default
+			}
+			continue
+		}
+
+		arg := ctx.Args[i]
+		if p.Rest && len(ctx.Args) > i+1 {
+			// keep the input's original spacing and quoting, if the arguments can be found in RawArgs
+			// (flags may be anywhere in it, so they can't)
+			arg = strings.Join(ctx.Args[i:], " ")
+			if ctx.Flags == nil {
+				if rest, ok := skipArgs(ctx.RawArgs, i); ok {
+					arg = rest
+				}
+			}
+		}
+
+		v, err := ctx.parseParam(p.Type, arg)
+		if err != nil {
+			e := newArgumentError(
+				MsgInvalidArg,
+				EscapeBackticks(arg), ctx.Router.ParamTypeString(ctx.Locale(), p.Type), p.Name,
+				ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.ParamUsage(),
+			)
+			e.Param, e.Value, e.Err = p.Name, arg, err
+			return e
+		}
+		ctx.Params[p.Name] = v
+	}
+
+	if (len(params) == 0 || !params[len(params)-1].Rest) && len(ctx.Args) > len(params) {
+		return newArgumentError(
+			MsgTooManyArgs,
+			len(params),
+			len(ctx.Args),
+			ctx.usagePrefix(), strings.Join(ctx.FullCommandPath, " "), ctx.Cmd.ParamUsage(),
+		)
+	}
+
+	return nil
+}
+
+// skipArgs returns raw without its first n arguments, splitting it the same way as Context.Args.
+// The rest of the input is returned as-is. ok is false if raw has an unclosed quote,
+// in which case the arguments weren't split the same way.
+func skipArgs(raw string, n int) (rest string, ok bool) {
+	var (
+		doubleQuoted, singleQuoted, escaped bool
+		inArg                               bool
+	)
+
+	for i, r := range raw {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && !singleQuoted:
+			escaped = true
+		case isArgSpace(r) && !singleQuoted && !doubleQuoted:
+			if inArg {
+				inArg = false
+				n--
+			}
+			continue
+		case (r == '"' || r == '“' || r == '”') && !singleQuoted:
+			doubleQuoted = !doubleQuoted
+		case (r == '\'' || r == '`' || r == '‘' || r == '’') && !doubleQuoted:
+			singleQuoted = !singleQuoted
+		}
+
+		if !inArg && n == 0 {
+			rest = raw[i:]
+			break
+		}
+		inArg = true
+	}
+
+	// with an unclosed quote, Context.Args is split on spaces instead
+	_, err := shellwords.Parse(raw)
+	return rest, err == nil
+}
+
+// isArgSpace returns true if r separates arguments.
+func isArgSpace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\r', '\n', '　':
+		return true
+	}
+	return false
+}
+
+// checkParams panics if the command's parameters are declared incorrectly.
+func (c *Command) checkParams() {
+	optional := false
+	for i, p := range c.Params {
+		if p.Rest && i != len(c.Params)-1 {
+			panic("command " + c.Name + ": parameter " + p.Name + " has Rest set, but isn't the last parameter")
+		}
+		if p.Required && optional {
+			panic("command " + c.Name + ": required parameter " + p.Name + " comes after an optional parameter")
+		}
+		optional = optional || !p.Required
+	}
+}
+
+func (ctx *Context) parseParam(t ParamType, arg string) (interface{}, error) {
+	switch t {
+	case IntParam:
+		return strconv.ParseInt(arg, 10, 64)
+	case NumberParam:
+		return strconv.ParseFloat(arg, 64)
+	case BoolParam:
+		return parseBool(arg)
+	case DurationParam:
+		return ParseDuration(arg)
+	case UserParam:
+		return ctx.ParseUser(arg)
+	case MemberParam:
+		if ctx.Guild == nil {
+			return nil, ErrMemberNotFound
+		}
+		return ctx.ParseMember(arg)
+	case RoleParam:
+		if ctx.Guild == nil {
+			return nil, ErrRoleNotFound
+		}
+		return ctx.ParseRole(arg)
+	case ChannelParam:
+		return ctx.ParseChannel(arg)
+	default:
+		return arg, nil
+	}
+}
+
+// parseParams parses the context's options into ctx.Params.
+func (ctx *SlashContext) parseParams() (err error) {
+	ctx.Params = ParamValues{}
+
+	for _, p := range ctx.Command.Params {
+		o := ctx.Option(p.Name)
+		if o.Name == "" {
+			if p.Default != nil {
+				ctx.Params[p.Name] = p.Default
+			}
+			continue
+		}
+
+		var v interface{}
+		switch p.Type {
+		case IntParam:
+			v, err = o.IntValue()
+		case NumberParam:
+			v, err = o.FloatValue()
+		case BoolParam:
+			v, err = o.BoolValue()
+		case DurationParam:
+			v, err = ParseDuration(o.String())
+		case UserParam:
+			v, err = o.User()
+		case MemberParam:
+			v, err = o.Member()
+		case RoleParam:
+			v, err = o.Role()
+		case ChannelParam:
+			v, err = o.Channel()
+		default:
+			v = o.String()
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "parsing option %v", p.Name)
+		}
+
+		ctx.Params[p.Name] = v
+	}
+
+	return nil
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+var durationRegex = regexp.MustCompile(`(\d+)\s*(w|d|h|m|s)`)
+
+// ParseDuration parses a duration such as "1h30m" or "2d".
+// Supported units are w (weeks), d (days), h (hours), m (minutes), and s (seconds).
+func ParseDuration(s string) (d time.Duration, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	matches := durationRegex.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 || strings.TrimSpace(durationRegex.ReplaceAllString(s, "")) != "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	for _, m := range matches {
+		i, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		switch m[2] {
+		case "w":
+			d += time.Duration(i) * 7 * 24 * time.Hour
+		case "d":
+			d += time.Duration(i) * 24 * time.Hour
+		case "h":
+			d += time.Duration(i) * time.Hour
+		case "m":
+			d += time.Duration(i) * time.Minute
+		case "s":
+			d += time.Duration(i) * time.Second
+		}
+	}
+	return d, nil
+}
@@ -35,13 +35,7 @@ func (ctx *Context) checkOwner() bool {
 		return true
 	}
 
-	for _, i := range ctx.Router.BotOwners {
-		if i == ctx.Author.ID.String() {
-			return true
-		}
-	}
-
-	return false
+	return ctx.Router.isOwner(ctx.Author.ID)
 }
 
 // CheckBotSendPerms checks if the bot can send messages in a channel
@@ -64,7 +58,7 @@ func (ctx *Context) CheckBotSendPerms(ch discord.ChannelID, e bool) bool {
 	// if the bot requires embed links but doesn't have it, return false
 	if e && !perms.Has(discord.PermissionEmbedLinks) {
 		// but we *can* send an error message (at least probably, we've checked for perms already)
-		ctx.State.SendMessage(ch, ":x: I do not have permission to send embeds in this channel. Please ensure I have the `Embed Links` permission here.")
+		ctx.State.SendMessage(ch, ctx.Translate(MsgNoEmbedPerms))
 		return false
 	}
 
@@ -0,0 +1,347 @@
+package bcr
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// Limits for guild prefixes set with GuildPrefixes.Add.
+const (
+	MaxGuildPrefixes  = 10
+	MaxPrefixLength   = 32
+	prefixCacheExpiry = 5 * time.Minute
+)
+
+// Errors returned by GuildPrefixes.Add and GuildPrefixes.Remove
+var (
+	ErrPrefixEmpty     = errors.Sentinel("prefix is empty")
+	ErrPrefixExists    = errors.Sentinel("prefix already exists")
+	ErrPrefixNotFound  = errors.Sentinel("prefix not found")
+	ErrTooManyPrefixes = errors.Sentinel("too many prefixes")
+	ErrPrefixTooLong   = errors.Sentinel("prefix too long")
+	ErrLastPrefix      = errors.Sentinel("can't remove the last prefix")
+)
+
+// PrefixStore stores guilds' custom prefixes.
+//
+// Implementations must be safe for concurrent use.
+type PrefixStore interface {
+	// GuildPrefixes returns the guild's custom prefixes. If the guild has none, it returns an empty slice and a nil error.
+	GuildPrefixes(guildID discord.GuildID) ([]string, error)
+	// SetGuildPrefixes sets the guild's custom prefixes. An empty slice resets the guild to the router's default prefixes.
+	SetGuildPrefixes(guildID discord.GuildID, prefixes []string) error
+}
+
+// MemoryPrefixStore is an in-memory PrefixStore. Prefixes are lost when the bot restarts.
+type MemoryPrefixStore struct {
+	mu       sync.RWMutex
+	prefixes map[discord.GuildID][]string
+}
+
+var _ PrefixStore = (*MemoryPrefixStore)(nil)
+
+// NewMemoryPrefixStore returns a new, empty in-memory prefix store.
+func NewMemoryPrefixStore() *MemoryPrefixStore {
+	return &MemoryPrefixStore{prefixes: make(map[discord.GuildID][]string)}
+}
+
+// GuildPrefixes implements PrefixStore.
+func (s *MemoryPrefixStore) GuildPrefixes(guildID discord.GuildID) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.prefixes[guildID]...), nil
+}
+
+// SetGuildPrefixes implements PrefixStore.
+func (s *MemoryPrefixStore) SetGuildPrefixes(guildID discord.GuildID, prefixes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(prefixes) == 0 {
+		delete(s.prefixes, guildID)
+		return nil
+	}
+
+	s.prefixes[guildID] = append([]string(nil), prefixes...)
+	return nil
+}
+
+// FilePrefixStore is a PrefixStore stored in a JSON file.
+// The file is read once when the store is created, so it shouldn't be shared between processes.
+type FilePrefixStore struct {
+	path string
+
+	mu       sync.RWMutex
+	prefixes map[discord.GuildID][]string
+}
+
+var _ PrefixStore = (*FilePrefixStore)(nil)
+
+// NewFilePrefixStore returns a prefix store stored in the file at path.
+// The file is created on the first write if it doesn't exist.
+func NewFilePrefixStore(path string) (*FilePrefixStore, error) {
+	s := &FilePrefixStore{
+		path:     path,
+		prefixes: make(map[discord.GuildID][]string),
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, errors.Wrap(err, "reading prefix file")
+	}
+
+	if len(b) != 0 {
+		if err = json.Unmarshal(b, &s.prefixes); err != nil {
+			return nil, errors.Wrap(err, "decoding prefix file")
+		}
+	}
+	return s, nil
+}
+
+// GuildPrefixes implements PrefixStore.
+func (s *FilePrefixStore) GuildPrefixes(guildID discord.GuildID) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.prefixes[guildID]...), nil
+}
+
+// SetGuildPrefixes implements PrefixStore.
+func (s *FilePrefixStore) SetGuildPrefixes(guildID discord.GuildID, prefixes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, hadOld := s.prefixes[guildID]
+	if len(prefixes) == 0 {
+		delete(s.prefixes, guildID)
+	} else {
+		s.prefixes[guildID] = append([]string(nil), prefixes...)
+	}
+
+	b, err := json.Marshal(s.prefixes)
+	if err == nil {
+		err = writeFileAtomic(s.path, b)
+	}
+	if err != nil {
+		// keep the in-memory state consistent with the file
+		if hadOld {
+			s.prefixes[guildID] = old
+		} else {
+			delete(s.prefixes, guildID)
+		}
+		return errors.Wrap(err, "writing prefix file")
+	}
+	return nil
+}
+
+type cachedPrefixes struct {
+	prefixes []string
+	expires  time.Time
+}
+
+// GuildPrefixes manages guilds' custom prefixes, caching them in memory.
+// Guilds without custom prefixes use Router.Prefixes.
+type GuildPrefixes struct {
+	r *Router
+
+	mu    sync.RWMutex
+	store PrefixStore
+	cache map[discord.GuildID]cachedPrefixes
+	// gens is incremented for a guild whenever its prefixes change, and epoch whenever the store changes,
+	// so Custom doesn't cache prefixes that were changed while it was fetching them.
+	gens  map[discord.GuildID]uint64
+	epoch uint64
+}
+
+func newGuildPrefixes(r *Router) *GuildPrefixes {
+	return &GuildPrefixes{
+		r:     r,
+		store: NewMemoryPrefixStore(),
+		cache: make(map[discord.GuildID]cachedPrefixes),
+		gens:  make(map[discord.GuildID]uint64),
+	}
+}
+
+// GuildPrefixes returns the router's guild prefix manager.
+func (r *Router) GuildPrefixes() *GuildPrefixes {
+	return r.guildPrefixes
+}
+
+// SetStore sets the store prefixes are saved in, and clears the cache.
+func (p *GuildPrefixes) SetStore(s PrefixStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.store = s
+	p.cache = make(map[discord.GuildID]cachedPrefixes)
+	p.epoch++
+}
+
+// Custom returns the guild's custom prefixes, or nil if it uses the default prefixes.
+func (p *GuildPrefixes) Custom(guildID discord.GuildID) ([]string, error) {
+	if !guildID.IsValid() {
+		return nil, nil
+	}
+
+	p.mu.RLock()
+	c, ok := p.cache[guildID]
+	store, gen, epoch := p.store, p.gens[guildID], p.epoch
+	p.mu.RUnlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.prefixes, nil
+	}
+
+	prefixes, err := store.GuildPrefixes(guildID)
+	if err != nil {
+		return nil, err
+	}
+	if len(prefixes) == 0 {
+		prefixes = nil
+	}
+
+	p.mu.Lock()
+	if p.gens[guildID] == gen && p.epoch == epoch {
+		p.cache[guildID] = cachedPrefixes{prefixes: prefixes, expires: time.Now().Add(prefixCacheExpiry)}
+	}
+	p.mu.Unlock()
+	return prefixes, nil
+}
+
+// Get returns the prefixes used in the guild, not including mention prefixes.
+// If the guild's prefixes couldn't be fetched, the default prefixes are returned.
+func (p *GuildPrefixes) Get(guildID discord.GuildID) []string {
+	custom, err := p.Custom(guildID)
+	if err != nil {
+		p.r.Logger.Error("getting prefixes for guild %v: %v", guildID, err)
+	}
+	if len(custom) != 0 {
+		return custom
+	}
+	return p.r.defaultPrefixes()
+}
+
+// Add adds a prefix to the guild. If the guild doesn't have custom prefixes yet, it starts with the default prefixes.
+// Unless PrefixOptions.CaseSensitive is set, prefixes are stored in lowercase.
+func (p *GuildPrefixes) Add(guildID discord.GuildID, prefix string) error {
+	prefix = p.r.normalizePrefix(prefix)
+	if prefix == "" {
+		return ErrPrefixEmpty
+	}
+	if len(prefix) > MaxPrefixLength {
+		return ErrPrefixTooLong
+	}
+
+	return p.update(guildID, func(prefixes []string) ([]string, error) {
+		for _, existing := range prefixes {
+			if existing == prefix {
+				return nil, ErrPrefixExists
+			}
+		}
+		if len(prefixes) >= MaxGuildPrefixes {
+			return nil, ErrTooManyPrefixes
+		}
+		return append(prefixes, prefix), nil
+	})
+}
+
+// Remove removes a prefix from the guild. If it was the guild's last prefix, the guild goes back to the default prefixes;
+// if the prefix is one of the defaults, it can't be removed, and ErrLastPrefix is returned.
+func (p *GuildPrefixes) Remove(guildID discord.GuildID, prefix string) error {
+	prefix = p.r.normalizePrefix(prefix)
+
+	return p.update(guildID, func(prefixes []string) ([]string, error) {
+		for i, existing := range prefixes {
+			if existing != prefix {
+				continue
+			}
+
+			prefixes = append(prefixes[:i:i], prefixes[i+1:]...)
+			// the guild would silently go back to the defaults, which include this prefix
+			if len(prefixes) == 0 {
+				for _, def := range p.r.defaultPrefixes() {
+					if p.r.normalizePrefix(def) == prefix {
+						return nil, ErrLastPrefix
+					}
+				}
+			}
+			return prefixes, nil
+		}
+		return nil, ErrPrefixNotFound
+	})
+}
+
+// Reset resets the guild to the default prefixes.
+func (p *GuildPrefixes) Reset(guildID discord.GuildID) error {
+	return p.update(guildID, func([]string) ([]string, error) { return nil, nil })
+}
+
+// update replaces the guild's prefixes with the result of fn, which is passed the guild's current prefixes.
+func (p *GuildPrefixes) update(guildID discord.GuildID, fn func([]string) ([]string, error)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current, err := p.store.GuildPrefixes(guildID)
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 {
+		current = p.r.defaultPrefixes()
+	}
+
+	prefixes, err := fn(append([]string(nil), current...))
+	if err != nil {
+		return err
+	}
+
+	if err = p.store.SetGuildPrefixes(guildID, prefixes); err != nil {
+		return err
+	}
+
+	delete(p.cache, guildID)
+	p.gens[guildID]++
+	return nil
+}
+
+// defaultPrefixes returns Router.Prefixes, without the bot's mention prefixes.
+func (r *Router) defaultPrefixes() []string {
+	mentions := r.mentionPrefixes()
+
+	prefixes := make([]string, 0, len(r.Prefixes))
+outer:
+	for _, p := range r.Prefixes {
+		for _, m := range mentions {
+			if p == m {
+				continue outer
+			}
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes
+}
+
+// mentionPrefixes returns the bot's mention prefixes, or nil if the bot user isn't known yet.
+func (r *Router) mentionPrefixes() []string {
+	if r.Bot == nil {
+		return nil
+	}
+
+	id := strconv.FormatUint(uint64(r.Bot.ID), 10)
+	return []string{"<@" + id + ">", "<@!" + id + ">"}
+}
+
+// PrefixesFor returns all prefixes that can be used in the given guild (or DM, if guildID is 0),
+// including mention prefixes.
+func (r *Router) PrefixesFor(guildID discord.GuildID) []string {
+	return append(r.GuildPrefixes().Get(guildID), r.mentionPrefixes()...)
+}
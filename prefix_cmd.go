@@ -0,0 +1,123 @@
+package bcr
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// PrefixCommand returns the "prefix" command, with "add", "remove", "list", and "reset" subcommands,
+// which let members with the Manage Server permission manage their server's prefixes.
+// The subcommands work as both prefix and slash commands.
+func (r *Router) PrefixCommand() *Command {
+	param := []Param{{Name: "prefix", Description: "The prefix", Type: StringParam, Required: true, Rest: true}}
+
+	cmd := &Command{
+		Name:             "prefix",
+		Aliases:          []string{"prefixes"},
+		Summary:          "Show or change this server's prefixes.",
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		Command:          func(ctx *Context) error { return r.listPrefixes(ctx) },
+	}
+
+	cmd.AddSubcommand(&Command{
+		Name:             "add",
+		Summary:          "Add a prefix to this server.",
+		Params:           param,
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		SlashCommand: func(ctx Contexter) error {
+			prefix := ctx.GetParams().String("prefix")
+			err := r.GuildPrefixes().Add(ctx.GetChannel().GuildID, prefix)
+			if err != nil {
+				return r.prefixError(ctx, prefix, err)
+			}
+			return ctx.SendX(ctx.Translate(MsgPrefixAdded, EscapeBackticks(r.normalizePrefix(prefix))))
+		},
+	})
+
+	cmd.AddSubcommand(&Command{
+		Name:             "remove",
+		Aliases:          []string{"rm", "delete"},
+		Summary:          "Remove a prefix from this server.",
+		Params:           param,
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		SlashCommand: func(ctx Contexter) error {
+			prefix := ctx.GetParams().String("prefix")
+			err := r.GuildPrefixes().Remove(ctx.GetChannel().GuildID, prefix)
+			if err != nil {
+				return r.prefixError(ctx, prefix, err)
+			}
+			return ctx.SendX(ctx.Translate(MsgPrefixRemoved, EscapeBackticks(r.normalizePrefix(prefix))))
+		},
+	})
+
+	cmd.AddSubcommand(&Command{
+		Name:             "list",
+		Summary:          "Show this server's prefixes.",
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		SlashCommand:     r.listPrefixes,
+	})
+
+	cmd.AddSubcommand(&Command{
+		Name:             "reset",
+		Summary:          "Reset this server's prefixes to the defaults.",
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		SlashCommand: func(ctx Contexter) error {
+			if err := r.GuildPrefixes().Reset(ctx.GetChannel().GuildID); err != nil {
+				return err
+			}
+			return ctx.SendX(ctx.Translate(MsgPrefixReset))
+		},
+	})
+
+	return cmd
+}
+
+// listPrefixes shows the prefixes used in the context's guild.
+func (r *Router) listPrefixes(ctx Contexter) error {
+	prefixes := r.GuildPrefixes().Get(ctx.GetChannel().GuildID)
+
+	lines := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		lines = append(lines, "- ``"+EscapeBackticks(p)+"``")
+	}
+
+	e := discord.Embed{
+		Title:       ctx.Translate(MsgPrefixListTitle),
+		Description: strings.Join(lines, "\n"),
+		Color:       r.EmbedColor,
+	}
+	if r.Bot != nil {
+		e.Footer = &discord.EmbedFooter{Text: ctx.Translate(MsgPrefixMention, "@"+r.Bot.Username)}
+	}
+
+	return ctx.SendX("", e)
+}
+
+// prefixError shows errors returned by GuildPrefixes.Add and GuildPrefixes.Remove to the user.
+// Other errors are returned as-is.
+func (r *Router) prefixError(ctx Contexter, prefix string, err error) error {
+	prefix = EscapeBackticks(r.normalizePrefix(prefix))
+
+	switch {
+	case errors.Is(err, ErrPrefixEmpty):
+		return ctx.SendEphemeral(ctx.Translate(MsgPrefixEmpty))
+	case errors.Is(err, ErrPrefixExists):
+		return ctx.SendEphemeral(ctx.Translate(MsgPrefixExists, prefix))
+	case errors.Is(err, ErrPrefixNotFound):
+		return ctx.SendEphemeral(ctx.Translate(MsgPrefixNotFound, prefix))
+	case errors.Is(err, ErrTooManyPrefixes):
+		return ctx.SendEphemeral(ctx.Translate(MsgPrefixTooMany, MaxGuildPrefixes))
+	case errors.Is(err, ErrPrefixTooLong):
+		return ctx.SendEphemeral(ctx.Translate(MsgPrefixTooLong, MaxPrefixLength))
+	case errors.Is(err, ErrLastPrefix):
+		return ctx.SendEphemeral(ctx.Translate(MsgPrefixLast, prefix))
+	}
+	return err
+}
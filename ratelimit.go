@@ -0,0 +1,305 @@
+package bcr
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+)
+
+// RateLimit is a token bucket limit: up to Uses commands can be used at once,
+// and the bucket refills at a rate of Uses per Window. A zero RateLimit is disabled.
+type RateLimit struct {
+	Uses   int
+	Window time.Duration
+}
+
+func (l RateLimit) enabled() bool { return l.Uses > 0 && l.Window > 0 }
+
+// RateLimitScope is the scope of a router-wide rate limit.
+type RateLimitScope int
+
+// Rate limit scopes
+const (
+	RateLimitUser RateLimitScope = iota
+	RateLimitChannel
+	RateLimitGuild
+)
+
+func (s RateLimitScope) String() string {
+	switch s {
+	case RateLimitChannel:
+		return "channel"
+	case RateLimitGuild:
+		return "guild"
+	}
+	return "user"
+}
+
+// AntiSpam configures the router-wide rate limits, which apply to all prefix and slash commands combined.
+// They're checked before a context is created, so they also limit the work done by spammed commands.
+type AntiSpam struct {
+	// User, Channel, and Guild are the rate limits per user, per channel, and per guild.
+	User    RateLimit
+	Channel RateLimit
+	Guild   RateLimit
+
+	// Response is the message sent when a user is rate limited. It's sent at most once per rate limit window;
+	// later slash commands in the same window are acknowledged without a response.
+	// If empty, the translated MsgSlowDown message is used.
+	Response string
+
+	// OnLimit is called every time a command is rejected by a rate limit, for example to blacklist repeat offenders.
+	OnLimit func(*RateLimitEvent)
+	// OffenseWindow is the window RateLimitEvent.Offenses is counted over. If 0, it's 10 minutes.
+	OffenseWindow time.Duration
+}
+
+// RateLimitEvent is a command rejected by the router's rate limits.
+type RateLimitEvent struct {
+	UserID    discord.UserID
+	ChannelID discord.ChannelID
+	GuildID   discord.GuildID
+
+	// Scope is the rate limit that was hit. If more than one was, it's the narrowest.
+	Scope RateLimitScope
+	// RetryAfter is the time until a command can be used again.
+	RetryAfter time.Duration
+	// Offenses is the number of times the user has been rate limited in the last AntiSpam.OffenseWindow, including this one.
+	Offenses int
+}
+
+type tokenBucket struct {
+	tokens   float64
+	last     time.Time
+	notified time.Time
+}
+
+// refill adds the tokens gained since the last refill.
+func (b *tokenBucket) refill(l RateLimit, now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * float64(l.Uses) / l.Window.Seconds()
+	if b.tokens > float64(l.Uses) {
+		b.tokens = float64(l.Uses)
+	}
+	b.last = now
+}
+
+// wait returns the time until the bucket has a token.
+func (b *tokenBucket) wait(l RateLimit) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / float64(l.Uses) * float64(l.Window))
+}
+
+type offenseCount struct {
+	count int
+	reset time.Time
+}
+
+// rateLimiter holds the state of the router's rate limits.
+type rateLimiter struct {
+	mu       sync.Mutex
+	buckets  map[rateLimitKey]*tokenBucket
+	offenses map[discord.UserID]*offenseCount
+	pruned   time.Time
+}
+
+type rateLimitKey struct {
+	scope RateLimitScope
+	id    discord.Snowflake
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:  make(map[rateLimitKey]*tokenBucket),
+		offenses: make(map[discord.UserID]*offenseCount),
+	}
+}
+
+// take takes a token from the user's, channel's, and guild's buckets.
+// If any of them are empty, no tokens are taken, and an event is returned.
+// notify is true if the user should be sent a response.
+func (l *rateLimiter) take(cfg *AntiSpam, userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID) (ev *RateLimitEvent, notify bool) {
+	now := time.Now()
+
+	type limited struct {
+		scope  RateLimitScope
+		limit  RateLimit
+		bucket *tokenBucket
+	}
+	var scopes []limited
+
+	add := func(scope RateLimitScope, limit RateLimit, id discord.Snowflake) {
+		if !limit.enabled() || !id.IsValid() {
+			return
+		}
+
+		k := rateLimitKey{scope, id}
+		b, ok := l.buckets[k]
+		if !ok {
+			b = &tokenBucket{tokens: float64(limit.Uses), last: now}
+			l.buckets[k] = b
+		}
+		b.refill(limit, now)
+		scopes = append(scopes, limited{scope, limit, b})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(cfg, now)
+
+	add(RateLimitUser, cfg.User, discord.Snowflake(userID))
+	add(RateLimitChannel, cfg.Channel, discord.Snowflake(channelID))
+	add(RateLimitGuild, cfg.Guild, discord.Snowflake(guildID))
+
+	for _, s := range scopes {
+		wait := s.bucket.wait(s.limit)
+		if wait == 0 {
+			continue
+		}
+
+		// only notify the user once per window
+		if now.Sub(s.bucket.notified) >= s.limit.Window {
+			s.bucket.notified = now
+			notify = true
+		}
+
+		return &RateLimitEvent{
+			UserID:     userID,
+			ChannelID:  channelID,
+			GuildID:    guildID,
+			Scope:      s.scope,
+			RetryAfter: wait,
+			Offenses:   l.offend(cfg, userID, now),
+		}, notify
+	}
+
+	for _, s := range scopes {
+		s.bucket.tokens--
+	}
+	return nil, false
+}
+
+// offend records an offense for the user, returning their number of offenses in the current window.
+func (l *rateLimiter) offend(cfg *AntiSpam, userID discord.UserID, now time.Time) int {
+	window := cfg.OffenseWindow
+	if window <= 0 {
+		window = 10 * time.Minute
+	}
+
+	o, ok := l.offenses[userID]
+	if !ok || !now.Before(o.reset) {
+		o = &offenseCount{reset: now.Add(window)}
+		l.offenses[userID] = o
+	}
+	o.count++
+	return o.count
+}
+
+// prune deletes full buckets and expired offense counts, at most once a minute. l.mu must be held.
+func (l *rateLimiter) prune(cfg *AntiSpam, now time.Time) {
+	if now.Sub(l.pruned) < time.Minute {
+		return
+	}
+	l.pruned = now
+
+	for k, b := range l.buckets {
+		var limit RateLimit
+		switch k.scope {
+		case RateLimitUser:
+			limit = cfg.User
+		case RateLimitChannel:
+			limit = cfg.Channel
+		case RateLimitGuild:
+			limit = cfg.Guild
+		}
+
+		// a bucket that's been unused for a whole window is full, and can be recreated on demand
+		if !limit.enabled() || (now.Sub(b.last) >= limit.Window && now.Sub(b.notified) >= limit.Window) {
+			delete(l.buckets, k)
+		}
+	}
+
+	for id, o := range l.offenses {
+		if !now.Before(o.reset) {
+			delete(l.offenses, id)
+		}
+	}
+}
+
+// rateLimited checks the router's rate limits, calling AntiSpam.OnLimit if the user is rate limited.
+// If the user should be told to slow down, reply is called with the response;
+// otherwise, ack is called (if it's not nil) to silently acknowledge the command.
+// Returns true if the command should be ignored.
+func (r *Router) rateLimited(userID discord.UserID, channelID discord.ChannelID, guildID discord.GuildID, lang discord.Language, reply func(string) error, ack func() error) bool {
+	cfg := r.AntiSpam
+	if cfg == nil {
+		return false
+	}
+
+	ev, notify := r.rateLimits.take(cfg, userID, channelID, guildID)
+	if ev == nil {
+		return false
+	}
+
+	r.Logger.Debug("user %v rate limited (%v scope, %v offenses)", userID, ev.Scope, ev.Offenses)
+
+	if cfg.OnLimit != nil {
+		cfg.OnLimit(ev)
+	}
+
+	if notify {
+		msg := cfg.Response
+		if msg == "" {
+			msg = r.Translate(lang, MsgSlowDown, r.HumanizeDuration(lang, DurationPrecisionSeconds, ev.RetryAfter))
+		}
+
+		if err := reply(msg); err != nil {
+			r.Logger.Error("sending rate limit message to %v: %v", userID, err)
+		}
+	} else if ack != nil {
+		if err := ack(); err != nil {
+			r.Logger.Error("acknowledging rate limited command from %v: %v", userID, err)
+		}
+	}
+	return true
+}
+
+// messageRateLimited checks the router's rate limits for a message that matched a prefix.
+func (r *Router) messageRateLimited(m *gateway.MessageCreateEvent) bool {
+	if r.AntiSpam == nil {
+		return false
+	}
+
+	var lang discord.Language
+	if r.LocaleFunc != nil {
+		lang = r.LocaleFunc(m.GuildID, m.Author.ID)
+	}
+
+	return r.rateLimited(m.Author.ID, m.ChannelID, m.GuildID, lang, func(msg string) error {
+		s, _ := r.StateFromGuildID(m.GuildID)
+		_, err := s.SendMessage(m.ChannelID, msg)
+		return err
+	}, nil)
+}
+
+// ackInteraction returns a function acknowledging the interaction without a visible response,
+// so the user doesn't see it fail. The deferred response is deleted immediately.
+func (r *Router) ackInteraction(ev *gateway.InteractionCreateEvent) func() error {
+	return func() error {
+		s, _ := r.StateFromGuildID(ev.GuildID)
+
+		err := s.RespondInteraction(ev.ID, ev.Token, api.InteractionResponse{
+			Type: api.DeferredMessageInteractionWithSource,
+			Data: &api.InteractionResponseData{Flags: api.EphemeralResponse},
+		})
+		if err != nil {
+			return err
+		}
+		return s.DeleteInteractionResponse(ev.AppID, ev.Token)
+	}
+}
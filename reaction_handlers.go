@@ -47,7 +47,10 @@ func (r *Router) ReactionAdd(e *gateway.MessageReactionAddEvent) {
 		}
 		// run the handler
 		// fork this off to a goroutine to unlock the reaction mutex immediately
-		go v.fn(v.ctx)
+		go r.safeRun(commandIncident("reaction handler", v.ctx), v.ctx.sendFunc(), func() error {
+			v.fn(v.ctx)
+			return nil
+		})
 
 		// if the handler should be deleted after running, do that
 		if v.deleteOnTrigger {
@@ -78,7 +81,10 @@ func (r *Router) ReactionRemove(ev *gateway.MessageReactionRemoveEvent) {
 
 		// run the handler
 		// fork this off to a goroutine to unlock the reaction mutex immediately
-		go v.fn(v.ctx)
+		go r.safeRun(commandIncident("reaction handler", v.ctx), v.ctx.sendFunc(), func() error {
+			v.fn(v.ctx)
+			return nil
+		})
 
 		// if the handler should be deleted after running, do that
 		if v.deleteOnTrigger {
@@ -1,134 +1,273 @@
 package bcr
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/discord"
-	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/diamondburned/arikawa/v3/state"
 	"github.com/starshine-sys/snowflake/v2"
 )
 
 // SyncCommands syncs slash commands in the given guilds.
-// If no guilds are given, slash commands are synced globally.
-// Router.Bot *must* be set before calling this function or it will panic!
-func (r *Router) SyncCommands(guildIDs ...discord.GuildID) (err error) {
-	r.cmdMu.Lock()
-	cmds := []*Command{}
-	for _, cmd := range r.cmds {
-		if cmd.Options != nil && !inCmds(cmds, cmd.id) {
-			cmds = append(cmds, cmd)
+// If no guilds are given, slash commands are synced globally,
+// and commands scoped to specific guilds (with Command.Guilds or Group.Guilds) are synced in those guilds.
+// Guilds that had scoped commands synced earlier by this router, but aren't referenced by any command anymore,
+// are synced too, clearing their commands. To clear guilds synced by a previous run, use ClearCommands.
+// If guilds are given, each guild gets both the unscoped commands and the commands scoped to that guild.
+//
+// The current commands are fetched first, and compared to the router's commands.
+// Commands are only overwritten if anything changed, otherwise no further requests are made.
+// The returned SyncResult contains the differences that were applied.
+//
+// Router.Bot *must* be set before calling this function, or it will return ErrNoBotUser.
+// Errors returned by Discord are returned as a *SyncError.
+func (r *Router) SyncCommands(guildIDs ...discord.GuildID) (*SyncResult, error) {
+	return r.syncCommands(false, guildIDs)
+}
+
+// DiffCommands is a dry run of SyncCommands:
+// it fetches the current commands and returns the differences, but doesn't change any commands.
+func (r *Router) DiffCommands(guildIDs ...discord.GuildID) (*SyncResult, error) {
+	return r.syncCommands(true, guildIDs)
+}
+
+func (r *Router) syncCommands(dryRun bool, guildIDs []discord.GuildID) (res *SyncResult, err error) {
+	if r.Bot == nil {
+		return nil, ErrNoBotUser
+	}
+
+	// rebuild slash groups, in case subcommands were added since they were last built
+	r.invalidateSlashGroups()
+
+	slashCmds := r.slashCommandData()
+	res = &SyncResult{
+		DryRun: dryRun,
+		Guilds: map[discord.GuildID]*CommandDiff{},
+	}
+
+	if len(guildIDs) == 0 {
+		res.Global, err = r.syncCommandsIn(dryRun, slashCmds.global(), 0)
+		if err != nil {
+			return res, err
+		}
+
+		for _, guildID := range r.scopedGuilds(slashCmds.guilds()) {
+			cmds := slashCmds.scopedTo(guildID)
+
+			res.Guilds[guildID], err = r.syncCommandsIn(dryRun, cmds, guildID)
+			if err != nil {
+				return res, err
+			}
+
+			if !dryRun {
+				r.setSynced(guildID, len(cmds) != 0)
+			}
 		}
+		return res, nil
 	}
-	r.cmdMu.Unlock()
 
-	slashCmds := []api.CreateCommandData{}
-	for _, cmd := range cmds {
-		slashCmds = append(slashCmds, api.CreateCommandData{
-			Type:        discord.ChatInputCommand,
-			Name:        strings.ToLower(cmd.Name),
-			Description: cmd.Summary,
-			Options:     *cmd.Options,
-		})
+	for _, guildID := range guildIDs {
+		res.Guilds[guildID], err = r.syncCommandsIn(dryRun, append(slashCmds.global(), slashCmds.scopedTo(guildID)...), guildID)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// ClearCommands removes all of the bot's commands in the given guilds.
+// This is useful for guilds that scoped commands were synced to before, but that no command references anymore.
+func (r *Router) ClearCommands(guildIDs ...discord.GuildID) (*SyncResult, error) {
+	if r.Bot == nil {
+		return nil, ErrNoBotUser
 	}
-	for _, g := range r.SlashGroups {
-		slashCmds = append(slashCmds, g.Command())
+
+	res := &SyncResult{
+		Guilds: map[discord.GuildID]*CommandDiff{},
 	}
 
-	if len(guildIDs) > 0 {
-		return r.syncCommandsIn(slashCmds, guildIDs)
+	var err error
+	for _, guildID := range guildIDs {
+		res.Guilds[guildID], err = r.syncCommandsIn(false, []api.CreateCommandData{}, guildID)
+		if err != nil {
+			return res, err
+		}
+		r.setSynced(guildID, false)
 	}
-	return r.syncCommandsGlobal(slashCmds)
+	return res, nil
 }
 
-func inCmds(cmds []*Command, id snowflake.ID) bool {
-	for _, cmd := range cmds {
-		if cmd.id == id {
-			return true
+// scopedGuilds returns the given guilds, and any guilds scoped commands were previously synced to.
+func (r *Router) scopedGuilds(guildIDs []discord.GuildID) []discord.GuildID {
+	r.syncedGuildMu.Lock()
+	defer r.syncedGuildMu.Unlock()
+
+	for id := range r.syncedGuilds {
+		if !guildInSlice(id, guildIDs) {
+			guildIDs = append(guildIDs, id)
 		}
 	}
-	return false
+	return guildIDs
 }
 
-func (r *Router) syncCommandsGlobal(cmds []api.CreateCommandData) (err error) {
-	appID := discord.AppID(r.Bot.ID)
-	s, _ := r.StateFromGuildID(0)
+func (r *Router) setSynced(guildID discord.GuildID, synced bool) {
+	r.syncedGuildMu.Lock()
+	defer r.syncedGuildMu.Unlock()
 
-	deleted := []discord.CommandID{}
-	current, err := s.Commands(appID)
-	if err != nil {
-		return err
+	if synced {
+		r.syncedGuilds[guildID] = struct{}{}
+	} else {
+		delete(r.syncedGuilds, guildID)
 	}
+}
 
-	for _, c := range current {
-		if !in(cmds, c.Name) {
-			deleted = append(deleted, c.ID)
+// scopedCommand is an application command, and the guilds it's scoped to (if any).
+type scopedCommand struct {
+	data   api.CreateCommandData
+	guilds []discord.GuildID
+}
+
+type scopedCommands []scopedCommand
+
+// global returns all unscoped commands.
+func (cmds scopedCommands) global() []api.CreateCommandData {
+	out := []api.CreateCommandData{}
+	for _, c := range cmds {
+		if len(c.guilds) == 0 {
+			out = append(out, c.data)
 		}
 	}
+	return out
+}
 
-	for _, id := range deleted {
-		err = s.DeleteCommand(appID, id)
-		if err != nil {
-			return err
+// scopedTo returns all commands scoped to the given guild.
+func (cmds scopedCommands) scopedTo(guildID discord.GuildID) []api.CreateCommandData {
+	out := []api.CreateCommandData{}
+	for _, c := range cmds {
+		if guildInSlice(guildID, c.guilds) {
+			out = append(out, c.data)
+		}
+	}
+	return out
+}
+
+// guilds returns all guilds any command is scoped to.
+func (cmds scopedCommands) guilds() []discord.GuildID {
+	out := []discord.GuildID{}
+	for _, c := range cmds {
+		for _, g := range c.guilds {
+			if !guildInSlice(g, out) {
+				out = append(out, g)
+			}
 		}
 	}
+	return out
+}
 
-	_, err = s.BulkOverwriteCommands(appID, cmds)
-	if err != nil {
-		switch err := err.(type) {
-		case *httputil.HTTPError:
-			fmt.Printf("Discord returned code %d, body %s\n", err.Status, string(err.Body))
+func guildInSlice(id discord.GuildID, s []discord.GuildID) bool {
+	for _, g := range s {
+		if g == id {
+			return true
+		}
+	}
+	return false
+}
+
+// slashCommandData returns all of the router's application commands.
+func (r *Router) slashCommandData() scopedCommands {
+	r.cmdMu.Lock()
+	cmds := []*Command{}
+	for _, cmd := range r.cmds {
+		// commands with slash subcommands are synced as groups instead
+		if _, ok := cmd.slashGroup(true); ok {
+			continue
+		}
+
+		if _, ok := cmd.slashOptions(); ok && !inCmds(cmds, cmd.id) {
+			cmds = append(cmds, cmd)
 		}
 	}
+	r.cmdMu.Unlock()
+
+	slashCmds := scopedCommands{}
+	for _, cmd := range cmds {
+		options, _ := cmd.slashOptions()
+		perms, noDM := cmd.slashPermissions()
+
+		slashCmds = append(slashCmds, scopedCommand{api.CreateCommandData{
+			Type:                     discord.ChatInputCommand,
+			Name:                     strings.ToLower(cmd.Name),
+			Description:              cmd.Summary,
+			Options:                  options,
+			NameLocalizations:        cmd.NameLocalizations,
+			DescriptionLocalizations: cmd.DescriptionLocalizations,
+			DefaultMemberPermissions: perms,
+			NoDMPermission:           noDM,
+		}, cmd.Guilds})
+	}
+	for _, g := range r.slashGroups() {
+		slashCmds = append(slashCmds, scopedCommand{g.Command(), g.Guilds})
+	}
+	for _, cmd := range r.ContextCommands() {
+		perms, noDM := cmd.slashPermissions()
 
-	return
+		slashCmds = append(slashCmds, scopedCommand{api.CreateCommandData{
+			Type:                     cmd.Type,
+			Name:                     cmd.Name,
+			NameLocalizations:        cmd.NameLocalizations,
+			DefaultMemberPermissions: perms,
+			NoDMPermission:           noDM,
+		}, cmd.Guilds})
+	}
+
+	return slashCmds
 }
 
-func in(cmds []api.CreateCommandData, name string) bool {
+func inCmds(cmds []*Command, id snowflake.ID) bool {
 	for _, cmd := range cmds {
-		if cmd.Name == name {
+		if cmd.id == id {
 			return true
 		}
 	}
 	return false
 }
 
-func (r *Router) syncCommandsIn(cmds []api.CreateCommandData, guildIDs []discord.GuildID) (err error) {
+// syncCommandsIn syncs commands in the given guild, or globally if guildID is 0.
+func (r *Router) syncCommandsIn(dryRun bool, cmds []api.CreateCommandData, guildID discord.GuildID) (*CommandDiff, error) {
 	appID := discord.AppID(r.Bot.ID)
+	s, _ := r.StateFromGuildID(guildID)
 
-	for _, guild := range guildIDs {
-		s, _ := r.StateFromGuildID(guild)
-
-		deleted := []discord.CommandID{}
-		current, err := s.GuildCommands(appID, guild)
-		if err != nil {
-			return err
-		}
-
-		for _, c := range current {
-			if !in(cmds, c.Name) {
-				deleted = append(deleted, c.ID)
-			}
-		}
+	current, err := fetchCommands(s, appID, guildID)
+	if err != nil {
+		return nil, newSyncError(guildID, "fetching commands", err)
+	}
 
-		for _, id := range deleted {
-			err = s.DeleteGuildCommand(appID, guild, id)
-			if err != nil {
-				return err
-			}
-		}
+	diff := diffCommands(current, cmds, !guildID.IsValid())
+	if dryRun || diff.Empty() {
+		return diff, nil
+	}
 
-		_, err = s.BulkOverwriteGuildCommands(appID, guild, cmds)
-		if err != nil {
-			switch err := err.(type) {
-			case *httputil.HTTPError:
-				fmt.Printf("Discord returned code %d, body %s\n", err.Status, string(err.Body))
-			}
+	if guildID.IsValid() {
+		_, err = s.BulkOverwriteGuildCommands(appID, guildID, cmds)
+	} else {
+		_, err = s.BulkOverwriteCommands(appID, cmds)
+	}
+	if err != nil {
+		return diff, newSyncError(guildID, "overwriting commands", err)
+	}
+	return diff, nil
+}
 
-			return err
-		}
+// fetchCommands fetches the current commands in the given guild, or globally if guildID is 0.
+// Unlike (*api.Client).Commands, this includes localizations.
+func fetchCommands(s *state.State, appID discord.AppID, guildID discord.GuildID) (cmds []discord.Command, err error) {
+	url := api.EndpointApplications + appID.String()
+	if guildID.IsValid() {
+		url += "/guilds/" + guildID.String()
 	}
+	url += "/commands?with_localizations=true"
 
-	return
+	return cmds, s.RequestJSON(&cmds, "GET", url)
 }
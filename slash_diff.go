@@ -0,0 +1,386 @@
+package bcr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+)
+
+// SyncResult is the result of a SyncCommands or DiffCommands call.
+type SyncResult struct {
+	// DryRun is true if no commands were changed.
+	DryRun bool
+
+	// Global is the diff for global commands, or nil if global commands weren't synced.
+	Global *CommandDiff
+	// Guilds are the diffs for guild commands, by guild ID.
+	Guilds map[discord.GuildID]*CommandDiff
+}
+
+// Changed returns true if any commands were (or, for a dry run, would be) changed.
+func (res *SyncResult) Changed() bool {
+	if res.Global != nil && !res.Global.Empty() {
+		return true
+	}
+
+	for _, d := range res.Guilds {
+		if !d.Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandDiff is the difference between the current commands and the router's commands in a single scope.
+type CommandDiff struct {
+	// Added and Removed are the names of added and removed commands.
+	// User and message commands have their type appended to their name.
+	Added   []string
+	Removed []string
+	Changed []CommandChange
+}
+
+// Empty returns true if the diff has no changes.
+func (d *CommandDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d *CommandDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+
+	var b strings.Builder
+	for _, n := range d.Added {
+		b.WriteString("+ " + n + "\n")
+	}
+	for _, n := range d.Removed {
+		b.WriteString("- " + n + "\n")
+	}
+	for _, c := range d.Changed {
+		b.WriteString("~ " + c.String() + "\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// CommandChange is a single changed command.
+// Option paths include subcommand groups and subcommands, separated by spaces.
+type CommandChange struct {
+	Name string
+	// Fields are the names of changed command fields, such as "description" or "default_member_permissions".
+	Fields []string
+
+	AddedOptions   []string
+	ChangedOptions []string
+	RemovedOptions []string
+}
+
+func (c CommandChange) String() string {
+	s := []string{}
+	if len(c.Fields) != 0 {
+		s = append(s, "fields: "+strings.Join(c.Fields, ", "))
+	}
+	if len(c.AddedOptions) != 0 {
+		s = append(s, "added options: "+strings.Join(c.AddedOptions, ", "))
+	}
+	if len(c.ChangedOptions) != 0 {
+		s = append(s, "changed options: "+strings.Join(c.ChangedOptions, ", "))
+	}
+	if len(c.RemovedOptions) != 0 {
+		s = append(s, "removed options: "+strings.Join(c.RemovedOptions, ", "))
+	}
+	return c.Name + " (" + strings.Join(s, "; ") + ")"
+}
+
+// SyncError is returned by SyncCommands and DiffCommands if a request to Discord fails.
+type SyncError struct {
+	// GuildID is the guild the error occurred in, or 0 for global commands.
+	GuildID discord.GuildID
+	// Op is the operation that failed.
+	Op string
+
+	// Status and Body are the HTTP status code and response body, if Discord returned an error response.
+	Status int
+	Body   []byte
+
+	Err error
+}
+
+func newSyncError(guildID discord.GuildID, op string, err error) *SyncError {
+	e := &SyncError{
+		GuildID: guildID,
+		Op:      op,
+		Err:     err,
+	}
+
+	var httpErr *httputil.HTTPError
+	if errors.As(err, &httpErr) {
+		e.Status = httpErr.Status
+		e.Body = httpErr.Body
+	}
+	return e
+}
+
+func (e *SyncError) Error() string {
+	scope := "global commands"
+	if e.GuildID.IsValid() {
+		scope = "commands in guild " + e.GuildID.String()
+	}
+
+	if e.Status != 0 {
+		return fmt.Sprintf("syncing %v: %v: Discord returned code %d, body %s", scope, e.Op, e.Status, string(e.Body))
+	}
+	return fmt.Sprintf("syncing %v: %v: %v", scope, e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SyncError) Unwrap() error { return e.Err }
+
+func commandKey(t discord.CommandType, name string) string {
+	switch t {
+	case discord.UserCommand:
+		return name + " (user command)"
+	case discord.MessageCommand:
+		return name + " (message command)"
+	}
+	return name
+}
+
+// diffCommands compares the current commands with the new commands.
+// DM permissions are only compared for global commands.
+func diffCommands(current []discord.Command, cmds []api.CreateCommandData, global bool) *CommandDiff {
+	d := &CommandDiff{}
+
+	old := map[string]discord.Command{}
+	for _, c := range current {
+		old[commandKey(c.Type, c.Name)] = c
+	}
+
+	seen := map[string]bool{}
+	for _, c := range cmds {
+		t := c.Type
+		if t == 0 {
+			t = discord.ChatInputCommand
+		}
+		key := commandKey(t, c.Name)
+		seen[key] = true
+
+		o, ok := old[key]
+		if !ok {
+			d.Added = append(d.Added, key)
+			continue
+		}
+
+		ch := CommandChange{Name: key}
+		if o.Description != c.Description {
+			ch.Fields = append(ch.Fields, "description")
+		}
+		if !localesEqual(o.NameLocalizations, c.NameLocalizations) {
+			ch.Fields = append(ch.Fields, "name_localizations")
+		}
+		if !localesEqual(o.DescriptionLocalizations, c.DescriptionLocalizations) {
+			ch.Fields = append(ch.Fields, "description_localizations")
+		}
+		if !permsEqual(o.DefaultMemberPermissions, c.DefaultMemberPermissions) {
+			ch.Fields = append(ch.Fields, "default_member_permissions")
+		}
+		if global && o.NoDMPermission != c.NoDMPermission {
+			ch.Fields = append(ch.Fields, "dm_permission")
+		}
+
+		ch.diffOptions("", o.Options, c.Options)
+
+		if len(ch.Fields) != 0 || len(ch.AddedOptions) != 0 || len(ch.ChangedOptions) != 0 || len(ch.RemovedOptions) != 0 {
+			d.Changed = append(d.Changed, ch)
+		}
+	}
+
+	for _, c := range current {
+		if key := commandKey(c.Type, c.Name); !seen[key] {
+			d.Removed = append(d.Removed, key)
+		}
+	}
+
+	return d
+}
+
+// diffOptions compares options recursively, adding changes to ch.
+func (ch *CommandChange) diffOptions(prefix string, current, options discord.CommandOptions) {
+	old := map[string]discord.CommandOption{}
+	oldOrder := []string{}
+	for _, o := range current {
+		old[o.Name()] = o
+		oldOrder = append(oldOrder, o.Name())
+	}
+
+	newOrder := []string{}
+	for _, o := range options {
+		path := prefix + o.Name()
+		newOrder = append(newOrder, o.Name())
+
+		oldOpt, ok := old[o.Name()]
+		if !ok {
+			ch.AddedOptions = append(ch.AddedOptions, path)
+			continue
+		}
+
+		if oldOpt.Type() != o.Type() {
+			ch.ChangedOptions = append(ch.ChangedOptions, path)
+			continue
+		}
+
+		switch o := o.(type) {
+		case *discord.SubcommandGroupOption:
+			oldGroup, ok := oldOpt.(*discord.SubcommandGroupOption)
+			if !ok {
+				ch.ChangedOptions = append(ch.ChangedOptions, path)
+				continue
+			}
+			if oldGroup.Description != o.Description ||
+				!localesEqual(oldGroup.OptionNameLocalizations, o.OptionNameLocalizations) ||
+				!localesEqual(oldGroup.DescriptionLocalizations, o.DescriptionLocalizations) {
+				ch.ChangedOptions = append(ch.ChangedOptions, path)
+			}
+
+			var oldSubs, subs discord.CommandOptions
+			for _, s := range oldGroup.Subcommands {
+				oldSubs = append(oldSubs, s)
+			}
+			for _, s := range o.Subcommands {
+				subs = append(subs, s)
+			}
+			ch.diffOptions(path+" ", oldSubs, subs)
+		case *discord.SubcommandOption:
+			oldSub, ok := oldOpt.(*discord.SubcommandOption)
+			if !ok {
+				ch.ChangedOptions = append(ch.ChangedOptions, path)
+				continue
+			}
+			if oldSub.Description != o.Description ||
+				!localesEqual(oldSub.OptionNameLocalizations, o.OptionNameLocalizations) ||
+				!localesEqual(oldSub.DescriptionLocalizations, o.DescriptionLocalizations) {
+				ch.ChangedOptions = append(ch.ChangedOptions, path)
+			}
+
+			var oldValues, values discord.CommandOptions
+			for _, v := range oldSub.Options {
+				oldValues = append(oldValues, v)
+			}
+			for _, v := range o.Options {
+				values = append(values, v)
+			}
+			ch.diffOptions(path+" ", oldValues, values)
+		default:
+			if !optionEqual(oldOpt, o) {
+				ch.ChangedOptions = append(ch.ChangedOptions, path)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, n := range newOrder {
+		seen[n] = true
+	}
+	for _, n := range oldOrder {
+		if !seen[n] {
+			ch.RemovedOptions = append(ch.RemovedOptions, prefix+n)
+		}
+	}
+
+	// if nothing else changed, the options might still have been reordered
+	if len(ch.AddedOptions) == 0 && len(ch.RemovedOptions) == 0 && strings.Join(oldOrder, "\x00") != strings.Join(newOrder, "\x00") {
+		ch.Fields = append(ch.Fields, strings.TrimSpace(prefix+"option order"))
+	}
+}
+
+// optionEqual returns true if the two options are the same.
+// Fields that are unset, null, or empty (such as a nil localization map and an empty one) are treated as equal,
+// as Discord returns some of these differently than they're sent.
+func optionEqual(a, b discord.CommandOption) bool {
+	av, err := normalizedOption(a)
+	if err != nil {
+		return false
+	}
+	bv, err := normalizedOption(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// normalizedOption returns the option's JSON representation with all empty values removed.
+func normalizedOption(o discord.CommandOption) (interface{}, error) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err = json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return dropEmpty(v), nil
+}
+
+// dropEmpty recursively removes null, false, empty string, empty object, and empty array values from objects.
+func dropEmpty(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			e = dropEmpty(e)
+			if isEmptyJSON(e) {
+				delete(v, k)
+			} else {
+				v[k] = e
+			}
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = dropEmpty(e)
+		}
+		return v
+	}
+	return v
+}
+
+func isEmptyJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func localesEqual(a, b discord.StringLocales) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func permsEqual(a, b *discord.Permissions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,85 @@
+package bcr
+
+import "github.com/diamondburned/arikawa/v3/discord"
+
+// slashPermissions returns the default member permissions and DM availability for the command.
+// These only hide the command in the client, the runtime checks are still run on execution.
+//
+// Owner-only commands are disabled for everyone except administrators by default,
+// as Discord has no way to limit a command to specific users globally.
+// Server admins can still allow them for the bot owners in the server's integration settings.
+func (c *Command) slashPermissions() (perms *discord.Permissions, noDM bool) {
+	// these are treated as guild only in execution as well
+	noDM = c.GuildOnly || c.GuildPermissions != 0 || c.Permissions != 0
+
+	if c.OwnerOnly {
+		p := discord.Permissions(0)
+		return &p, noDM
+	}
+
+	if p := c.GuildPermissions | c.Permissions; p != 0 {
+		return &p, noDM
+	}
+	return nil, noDM
+}
+
+// slashPermissions returns the default member permissions and DM availability for the group.
+// As Discord only allows these on top-level commands, these are the least restrictive permissions needed for any of the group's subcommands:
+// the group is only hidden from users who can't run *any* of them.
+func (g *Group) slashPermissions() (perms *discord.Permissions, noDM bool) {
+	cmds := g.allSubcommands()
+	if len(cmds) == 0 {
+		return nil, false
+	}
+
+	noDM = true
+	for _, cmd := range cmds {
+		if _, cmdNoDM := cmd.slashPermissions(); !cmdNoDM {
+			noDM = false
+			break
+		}
+	}
+
+	allOwner := true
+	common := discord.PermissionAll
+	for _, cmd := range cmds {
+		// owners might not have any permissions, so owner-only subcommands can't restrict the group,
+		// unless *every* subcommand is owner-only
+		if cmd.OwnerOnly {
+			continue
+		}
+		allOwner = false
+
+		// if any subcommand can be run by everyone, so can the group
+		p, _ := cmd.slashPermissions()
+		if p == nil {
+			return nil, noDM
+		}
+		common &= *p
+	}
+
+	if allOwner {
+		p := discord.Permissions(0)
+		return &p, noDM
+	}
+
+	// 0 means only administrators, which isn't what we want here
+	if common == 0 {
+		return nil, noDM
+	}
+	return &common, noDM
+}
+
+// allSubcommands returns all subcommands of the group, including those in subgroups.
+func (g *Group) allSubcommands() []*Command {
+	cmds := []*Command{}
+	for _, cmd := range g.Subcommands {
+		if cmd.SlashCommand != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	for _, sub := range g.Subgroups {
+		cmds = append(cmds, sub.allSubcommands()...)
+	}
+	return cmds
+}
@@ -0,0 +1,170 @@
+package bcr
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+type responseState int
+
+const (
+	responseNone responseState = iota
+	responseDeferred
+	responseSent
+)
+
+// slashResponse tracks whether an interaction has been responded to.
+// It's shared between copies of the same SlashContext.
+type slashResponse struct {
+	mu        sync.Mutex
+	state     responseState
+	ephemeral bool
+}
+
+func (ctx *SlashContext) response() *slashResponse {
+	if ctx.resp == nil {
+		ctx.resp = &slashResponse{}
+	}
+	return ctx.resp
+}
+
+// Responded returns true if the interaction has been responded to or deferred.
+func (ctx *SlashContext) Responded() bool {
+	resp := ctx.response()
+	resp.mu.Lock()
+	defer resp.mu.Unlock()
+
+	return resp.state != responseNone
+}
+
+// Defer defers the response to the interaction, showing a loading state to the user.
+// This gives the command up to 15 minutes to respond, instead of 3 seconds.
+// The next message sent (with Send, SendEphemeral, Respond, etc.) replaces the loading state.
+//
+// If ephemeral is true, the loading state (and the message replacing it) is only shown to the user.
+// Defer does nothing if the interaction was already responded to.
+func (ctx *SlashContext) Defer(ephemeral bool) error {
+	resp := ctx.response()
+	resp.mu.Lock()
+	defer resp.mu.Unlock()
+
+	if resp.state != responseNone {
+		return nil
+	}
+
+	data := api.InteractionResponse{Type: api.DeferredMessageInteractionWithSource}
+	if ephemeral {
+		data.Data = &api.InteractionResponseData{Flags: api.EphemeralResponse}
+	}
+
+	err := ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, data)
+	if err != nil {
+		return err
+	}
+
+	resp.state = responseDeferred
+	resp.ephemeral = ephemeral
+	return nil
+}
+
+// Respond sends a message in response to the interaction:
+// as the initial response if the interaction wasn't responded to yet, replacing the loading state if it was deferred,
+// and as a follow-up message otherwise.
+//
+// If the interaction was deferred with a different ephemeral setting than data, the loading state is deleted and a follow-up message is sent instead.
+func (ctx *SlashContext) Respond(data api.InteractionResponseData) (*discord.Message, error) {
+	return ctx.respond(data, true)
+}
+
+// respond sends a message, see Respond.
+// For initial responses, the created message is only fetched if wantMsg is true.
+func (ctx *SlashContext) respond(data api.InteractionResponseData, wantMsg bool) (*discord.Message, error) {
+	resp := ctx.response()
+	resp.mu.Lock()
+	defer resp.mu.Unlock()
+
+	appID := ctx.Event.AppID
+	ephemeral := data.Flags&api.EphemeralResponse != 0
+
+	switch resp.state {
+	case responseNone:
+		err := ctx.State.RespondInteraction(ctx.InteractionID, ctx.InteractionToken, api.InteractionResponse{
+			Type: api.MessageInteractionWithSource,
+			Data: &data,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.state = responseSent
+
+		if !wantMsg {
+			return nil, nil
+		}
+		return ctx.Original()
+
+	case responseDeferred:
+		resp.state = responseSent
+
+		if ephemeral == resp.ephemeral {
+			return ctx.State.EditInteractionResponse(appID, ctx.InteractionToken, api.EditInteractionResponseData{
+				Content:         data.Content,
+				Embeds:          data.Embeds,
+				Components:      data.Components,
+				AllowedMentions: data.AllowedMentions,
+				Files:           data.Files,
+			})
+		}
+
+		// the loading state can't change visibility, so replace it with a follow-up instead
+		if err := ctx.State.DeleteInteractionResponse(appID, ctx.InteractionToken); err != nil {
+			return nil, err
+		}
+	}
+
+	return ctx.State.FollowUpInteraction(appID, ctx.InteractionToken, data)
+}
+
+// markResponded marks the interaction as responded to, for responses not sent with respond.
+func (ctx *SlashContext) markResponded() {
+	resp := ctx.response()
+	resp.mu.Lock()
+	resp.state = responseSent
+	resp.mu.Unlock()
+}
+
+// EditFollowup edits a follow-up message.
+func (ctx *SlashContext) EditFollowup(id discord.MessageID, data api.EditInteractionResponseData) (*discord.Message, error) {
+	return ctx.State.EditInteractionFollowup(ctx.Event.AppID, id, ctx.InteractionToken, data)
+}
+
+// DeleteFollowup deletes a follow-up message.
+func (ctx *SlashContext) DeleteFollowup(id discord.MessageID) error {
+	return ctx.State.DeleteInteractionFollowup(ctx.Event.AppID, id, ctx.InteractionToken)
+}
+
+// DeleteOriginal deletes the original response.
+func (ctx *SlashContext) DeleteOriginal() error {
+	return ctx.State.DeleteInteractionResponse(ctx.Event.AppID, ctx.InteractionToken)
+}
+
+// startAutoDefer defers the interaction if it hasn't been responded to after the command's (or router's) AutoDefer duration.
+// The returned function stops the timer.
+func (r *Router) startAutoDefer(ctx *SlashContext, cmd *Command) (stop func()) {
+	d := cmd.AutoDefer
+	if d == 0 {
+		d = r.AutoDefer
+	}
+	if d <= 0 {
+		return func() {}
+	}
+
+	t := time.AfterFunc(d, func() {
+		if err := ctx.Defer(r.AutoDeferEphemeral); err != nil {
+			r.Logger.Error("auto-deferring interaction %v: %v", ctx.InteractionID, err)
+		}
+	})
+	return func() { t.Stop() }
+}
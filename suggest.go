@@ -0,0 +1,176 @@
+package bcr
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+)
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions, and transpositions of adjacent characters needed to turn a into b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// maxSuggestionDistance returns the maximum edit distance for a suggestion for the given input:
+// one edit for short names, up to three for long ones.
+func maxSuggestionDistance(input string) int {
+	n := len([]rune(input))/3 + 1
+	if n > 3 {
+		n = 3
+	}
+	return n
+}
+
+// suggestCommand returns the command whose name or alias is closest to name, or nil if none are close enough.
+// Hidden commands and commands not available in the guild are never suggested.
+func suggestCommand(name string, cmds map[string]*Command, mu *sync.RWMutex, guildID discord.GuildID) *Command {
+	name = strings.ToLower(name)
+	if name == "" {
+		return nil
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	// sort keys so ties are broken the same way every time
+	keys := make([]string, 0, len(cmds))
+	for k := range cmds {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var (
+		best     *Command
+		bestDist = maxSuggestionDistance(name) + 1
+	)
+	for _, k := range keys {
+		c := cmds[k]
+		if c.Hidden || !c.availableIn(guildID) {
+			continue
+		}
+
+		if d := editDistance(name, k); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// suggestionButtonID is the custom ID of the button running a suggested command.
+const suggestionButtonID = "bcr-run-suggestion"
+
+// suggest tells the user that the command at ctx.FullCommandPath + typo doesn't exist, suggesting c instead.
+// If Router.SuggestionButton is set, the message has a button that runs the suggested command with the same arguments.
+// cmds, mu, and mws are the layer the command is run in, as passed to execInner.
+func (r *Router) suggest(ctx *Context, typo string, c *Command, cmds map[string]*Command, mu *sync.RWMutex, mws []Middleware) error {
+	unknown := strings.Join(append(ctx.FullCommandPath[:len(ctx.FullCommandPath):len(ctx.FullCommandPath)], typo), " ")
+	suggestion := strings.Join(append(ctx.FullCommandPath[:len(ctx.FullCommandPath):len(ctx.FullCommandPath)], strings.ToLower(c.Name)), " ")
+
+	content := ctx.Translate(MsgSuggestion, EscapeBackticks(unknown), EscapeBackticks(suggestion))
+	if !r.SuggestionButton {
+		_, err := ctx.Send(content)
+		return err
+	}
+
+	msg, err := ctx.SendComponents(discord.Components(&discord.ButtonComponent{
+		Label:    ctx.Translate(MsgSuggestionButton, suggestion),
+		Style:    discord.PrimaryButtonStyle(),
+		CustomID: suggestionButtonID,
+	}), content)
+	if err != nil {
+		return err
+	}
+
+	// the suggested command runs in a copy of the context, as if it had been used instead
+	nctx := &Context{}
+	*nctx = *ctx
+	nctx.Command = strings.ToLower(c.Name)
+	nctx.FullCommandPath = append([]string(nil), ctx.FullCommandPath...)
+	nctx.commandPath = append([]string(nil), ctx.commandPath...)
+	nctx.limitPath = append([]string(nil), ctx.limitPath...)
+
+	// done is set by whichever of the click and the timeout happens first
+	var done int32
+
+	ctx.AddButtonHandler(msg.ID, ctx.Author.ID, suggestionButtonID, true, func(_ *Context, ev *gateway.InteractionCreateEvent) {
+		if !atomic.CompareAndSwapInt32(&done, 0, 1) {
+			return
+		}
+
+		err := ctx.State.RespondInteraction(ev.ID, ev.Token, api.InteractionResponse{
+			Type: api.UpdateMessage,
+			Data: &api.InteractionResponseData{
+				Components: &discord.ContainerComponents{},
+			},
+		})
+		if err != nil {
+			r.Logger.Error("responding to suggestion button: %v", err)
+		}
+
+		if err := r.execInner(nctx, cmds, mu, mws); err != nil && err != errCommandRun {
+			r.Logger.Error("running suggested command: %v", err)
+		}
+	})
+
+	// after the timeout, remove the button, unless it was already clicked.
+	// the handler is deleted directly, as its remove func would keep the whole context alive until then.
+	s, key := ctx.State, buttonKey{msg.ID, ctx.Author.ID, suggestionButtonID}
+	time.AfterFunc(r.ReactTimeout, func() {
+		if !atomic.CompareAndSwapInt32(&done, 0, 1) {
+			return
+		}
+
+		r.buttonMu.Lock()
+		delete(r.buttons, key)
+		r.buttonMu.Unlock()
+
+		_, err := s.EditMessageComplex(msg.ChannelID, msg.ID, api.EditMessageData{
+			Components: &discord.ContainerComponents{},
+		})
+		if err != nil {
+			r.Logger.Error("removing suggestion button: %v", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,139 @@
+package bcr
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// ToggleCommands returns the "disable", "enable", and "toggles" commands, which let members with the Manage Server permission
+// disable and re-enable commands and modules in their server. They work as both prefix and slash commands.
+// Add them with AddCommand, or as subcommands of another command. They can't be disabled themselves.
+func (r *Router) ToggleCommands() []*Command {
+	params := []Param{
+		{Name: "target", Description: "The command or module", Type: StringParam, Required: true},
+		{Name: "channel", Description: "Only in this channel", Type: ChannelParam},
+		{Name: "role", Description: "Only for members with this role", Type: RoleParam},
+	}
+
+	return []*Command{{
+		Name:             "disable",
+		Summary:          "Disable a command or module in this server, a channel, or for a role.",
+		Params:           params,
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		SlashCommand:     func(ctx Contexter) error { return r.toggleCommand(ctx, true) },
+		untoggleable:     true,
+	}, {
+		Name:             "enable",
+		Summary:          "Re-enable a disabled command or module.",
+		Params:           params,
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		SlashCommand:     func(ctx Contexter) error { return r.toggleCommand(ctx, false) },
+		untoggleable:     true,
+	}, {
+		Name:             "toggles",
+		Summary:          "Show the commands and modules disabled in this server.",
+		GuildOnly:        true,
+		GuildPermissions: discord.PermissionManageGuild,
+		SlashCommand:     r.listToggles,
+		untoggleable:     true,
+	}}
+}
+
+// toggleCommand disables or enables the command or module given in the context's parameters.
+func (r *Router) toggleCommand(ctx Contexter, disable bool) error {
+	if r.ToggleStore == nil {
+		return errors.New("router has no toggle store")
+	}
+
+	p := ctx.GetParams()
+	if p.Has("channel") && p.Has("role") {
+		return ctx.SendEphemeral(ctx.Translate(MsgToggleChannelAndRole))
+	}
+
+	target := p.String("target")
+	t, cmd, ok := r.resolveToggleTarget(target)
+	if !ok {
+		return ctx.SendEphemeral(ctx.Translate(MsgToggleUnknown, EscapeBackticks(target)))
+	}
+	if cmd != nil && cmd.untoggleable {
+		return ctx.SendEphemeral(ctx.Translate(MsgToggleCantDisable, toggleTarget(ctx, t)))
+	}
+	// disabling the parent (or module) of an untoggleable command would lock it out too
+	if disable && ((cmd != nil && hasUntoggleable(cmd)) || (cmd == nil && r.moduleHasUntoggleable(t.Module))) {
+		return ctx.SendEphemeral(ctx.Translate(MsgToggleCantDisable, toggleTarget(ctx, t)))
+	}
+
+	t.GuildID = ctx.GetChannel().GuildID
+	if ch := p.Channel("channel"); ch != nil {
+		t.ChannelID = ch.ID
+	}
+	if role := p.Role("role"); role != nil {
+		t.RoleID = role.ID
+	}
+
+	if disable {
+		if err := r.ToggleStore.AddToggle(t); err != nil {
+			return err
+		}
+		return ctx.SendX(ctx.Translate(MsgToggleDisabled, toggleTarget(ctx, t), toggleScope(ctx, t)))
+	}
+
+	ok, err := r.ToggleStore.RemoveToggle(t)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ctx.SendEphemeral(ctx.Translate(MsgToggleNotDisabled, toggleTarget(ctx, t), toggleScope(ctx, t)))
+	}
+	return ctx.SendX(ctx.Translate(MsgToggleEnabled, toggleTarget(ctx, t), toggleScope(ctx, t)))
+}
+
+// listToggles lists the toggles in the context's guild.
+func (r *Router) listToggles(ctx Contexter) error {
+	if r.ToggleStore == nil {
+		return errors.New("router has no toggle store")
+	}
+
+	toggles, err := r.ToggleStore.Toggles(ctx.GetChannel().GuildID)
+	if err != nil {
+		return err
+	}
+
+	if len(toggles) == 0 {
+		return ctx.SendX(ctx.Translate(MsgToggleNone))
+	}
+
+	lines := make([]string, 0, len(toggles))
+	for _, t := range toggles {
+		lines = append(lines, "- "+toggleTarget(ctx, t)+" "+toggleScope(ctx, t))
+	}
+
+	return ctx.SendX("", discord.Embed{
+		Title:       ctx.Translate(MsgToggleListTitle),
+		Description: truncate(strings.Join(lines, "\n"), 4096),
+		Color:       r.EmbedColor,
+	})
+}
+
+// toggleTarget returns the toggle's command or module, for messages.
+func toggleTarget(ctx Contexter, t Toggle) string {
+	if t.Module != "" {
+		return ctx.Translate(MsgToggleModule, t.Module)
+	}
+	return ctx.Translate(MsgToggleCommand, EscapeBackticks(t.Command))
+}
+
+// toggleScope returns where the toggle applies, for messages.
+func toggleScope(ctx Contexter, t Toggle) string {
+	switch {
+	case t.ChannelID.IsValid():
+		return ctx.Translate(MsgToggleInChannel, t.ChannelID.Mention())
+	case t.RoleID.IsValid():
+		return ctx.Translate(MsgToggleForRole, t.RoleID.Mention())
+	}
+	return ctx.Translate(MsgToggleInGuild)
+}
@@ -0,0 +1,324 @@
+package bcr
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// Toggle disables a command (and its subcommands) or a module in a guild.
+// If ChannelID is set, the toggle only applies in that channel (and threads in it);
+// if RoleID is set, it only applies to members with that role. Otherwise, it applies to the whole guild.
+type Toggle struct {
+	GuildID   discord.GuildID   `json:"guild_id"`
+	ChannelID discord.ChannelID `json:"channel_id,omitempty"`
+	RoleID    discord.RoleID    `json:"role_id,omitempty"`
+
+	// Command is the lowercase path to the command, separated by spaces (for example, "config prefix").
+	// Module is the name of a module, see Command.Module. Only one of these is set.
+	Command string `json:"command,omitempty"`
+	Module  string `json:"module,omitempty"`
+}
+
+// matches returns true if the toggle applies to the given command path and module.
+func (t Toggle) matches(path []string, module string) bool {
+	if t.Module != "" {
+		return strings.EqualFold(t.Module, module)
+	}
+
+	target := strings.Fields(t.Command)
+	if len(target) == 0 || len(target) > len(path) {
+		return false
+	}
+	for i := range target {
+		if !strings.EqualFold(target[i], path[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// appliesTo returns true if the toggle applies in the context's channel, to the context's user.
+func (t Toggle) appliesTo(ctx Contexter) bool {
+	if t.ChannelID.IsValid() {
+		ch := ctx.GetChannel()
+		parent := ctx.GetParentChannel()
+		if (ch == nil || ch.ID != t.ChannelID) && (parent == nil || parent.ID != t.ChannelID) {
+			return false
+		}
+	}
+
+	if t.RoleID.IsValid() {
+		m := ctx.GetMember()
+		if m == nil {
+			return false
+		}
+		for _, id := range m.RoleIDs {
+			if id == t.RoleID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ToggleStore stores disabled commands.
+// The default is an in-memory store (see NewMemoryToggleStore), which is cleared when the bot restarts.
+//
+// Implementations must be safe for concurrent use.
+type ToggleStore interface {
+	// Toggles returns the guild's toggles.
+	Toggles(guildID discord.GuildID) ([]Toggle, error)
+	// AddToggle adds a toggle. Adding a toggle that already exists should do nothing.
+	AddToggle(t Toggle) error
+	// RemoveToggle removes a toggle, returning false if it didn't exist.
+	RemoveToggle(t Toggle) (bool, error)
+}
+
+// MemoryToggleStore is an in-memory ToggleStore.
+type MemoryToggleStore struct {
+	mu      sync.RWMutex
+	toggles map[discord.GuildID][]Toggle
+}
+
+var _ ToggleStore = (*MemoryToggleStore)(nil)
+
+// NewMemoryToggleStore returns a new, empty in-memory toggle store.
+func NewMemoryToggleStore() *MemoryToggleStore {
+	return &MemoryToggleStore{toggles: make(map[discord.GuildID][]Toggle)}
+}
+
+// Toggles implements ToggleStore.
+func (s *MemoryToggleStore) Toggles(guildID discord.GuildID) ([]Toggle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Toggle(nil), s.toggles[guildID]...), nil
+}
+
+// AddToggle implements ToggleStore.
+func (s *MemoryToggleStore) AddToggle(t Toggle) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, old := range s.toggles[t.GuildID] {
+		if old == t {
+			return nil
+		}
+	}
+
+	s.toggles[t.GuildID] = append(s.toggles[t.GuildID], t)
+	return nil
+}
+
+// RemoveToggle implements ToggleStore.
+func (s *MemoryToggleStore) RemoveToggle(t Toggle) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	toggles := s.toggles[t.GuildID]
+	for i, old := range toggles {
+		if old == t {
+			s.toggles[t.GuildID] = append(toggles[:i:i], toggles[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DisabledError is returned when a command is disabled with a Toggle.
+// The default responder ignores these for prefix commands.
+type DisabledError struct {
+	Toggle Toggle
+}
+
+func (e *DisabledError) Error() string {
+	if e.Toggle.Module != "" {
+		return "module " + e.Toggle.Module + " is disabled"
+	}
+	return "command " + e.Toggle.Command + " is disabled"
+}
+
+// Message implements CheckError.
+func (*DisabledError) Message(ctx Contexter) string { return ctx.Translate(MsgCommandDisabled) }
+
+// checkToggles returns a *DisabledError if the command is disabled in the context's guild or channel, or for the context's user.
+func (r *Router) checkToggles(ctx Contexter, guildID discord.GuildID, path []string, c *Command) error {
+	if r.ToggleStore == nil || !guildID.IsValid() || c.untoggleable {
+		return nil
+	}
+
+	toggles, err := r.ToggleStore.Toggles(guildID)
+	if err != nil {
+		// don't block commands if the store is unavailable
+		r.Logger.Error("getting toggles for guild %v: %v", guildID, err)
+		return nil
+	}
+
+	module := c.Module
+	if module == "" {
+		module = r.commandModule(path)
+	}
+	for _, t := range toggles {
+		if t.matches(path, module) && t.appliesTo(ctx) {
+			return &DisabledError{Toggle: t}
+		}
+	}
+	return nil
+}
+
+// leadsToUntoggleable returns true if c is untoggleable, or args lead from it to an untoggleable subcommand.
+// Toggles aren't checked for these, so commands like enable can't be locked out by disabling their parent.
+func leadsToUntoggleable(c *Command, args []string) bool {
+	for !c.untoggleable {
+		if len(args) == 0 {
+			return false
+		}
+		if c = c.GetCommand(args[0]); c == nil {
+			return false
+		}
+		args = args[1:]
+	}
+	return true
+}
+
+// hasUntoggleable returns true if c or any of its subcommands are untoggleable.
+func hasUntoggleable(c *Command) bool {
+	if c.untoggleable {
+		return true
+	}
+	c.subMu.RLock()
+	subs := c.Subcommands()
+	c.subMu.RUnlock()
+
+	for _, sub := range subs {
+		if hasUntoggleable(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleHasUntoggleable returns true if the module contains an untoggleable command, or the parent of one.
+// Subcommands without a module of their own are in their parent's module.
+func (r *Router) moduleHasUntoggleable(module string) bool {
+	var walk func(c *Command, parent string) bool
+	walk = func(c *Command, parent string) bool {
+		m := c.Module
+		if m == "" {
+			m = parent
+		}
+		if strings.EqualFold(m, module) && hasUntoggleable(c) {
+			return true
+		}
+		c.subMu.RLock()
+		subs := c.Subcommands()
+		c.subMu.RUnlock()
+
+		for _, sub := range subs {
+			if walk(sub, m) {
+				return true
+			}
+		}
+		return false
+	}
+
+	r.cmdMu.RLock()
+	cmds := r.Commands()
+	r.cmdMu.RUnlock()
+
+	for _, c := range cmds {
+		if walk(c, "") {
+			return true
+		}
+	}
+	return false
+}
+
+// commandModule returns the module of the top-level command in path.
+func (r *Router) commandModule(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	if c := r.GetCommand(path[0]); c != nil {
+		return c.Module
+	}
+	return ""
+}
+
+// resolveToggleTarget resolves a command path or module name to a toggle target.
+// Command paths are checked first, then module names; the returned command is nil for modules.
+func (r *Router) resolveToggleTarget(s string) (t Toggle, c *Command, ok bool) {
+	if path := strings.Fields(s); len(path) != 0 {
+		if c, canonical := r.findCommand(path); c != nil {
+			return Toggle{Command: strings.ToLower(strings.Join(canonical, " "))}, c, true
+		}
+	}
+
+	// user and message command names can contain spaces, so they're matched as a whole
+	for _, c := range r.ContextCommands() {
+		if strings.EqualFold(c.Name, s) {
+			return Toggle{Command: strings.ToLower(strings.Join(strings.Fields(c.Name), " "))}, c, true
+		}
+	}
+
+	r.cmdMu.RLock()
+	cmds := r.Commands()
+	r.cmdMu.RUnlock()
+
+	for _, c := range cmds {
+		if c.Module != "" && strings.EqualFold(c.Module, s) {
+			return Toggle{Module: c.Module}, nil, true
+		}
+	}
+	return t, nil, false
+}
+
+// findCommand finds a command by path, through both prefix subcommands and slash command groups.
+// It also returns the canonical path to the command, with aliases (including those created with Router.Alias) resolved.
+func (r *Router) findCommand(path []string) (*Command, []string) {
+	if c := r.GetCommand(path[0]); c != nil {
+		canonical := append([]string(nil), c.canonicalPath()...)
+		for _, name := range path[1:] {
+			if c = c.GetCommand(name); c == nil {
+				return nil, nil
+			}
+			if c.aliasPath != nil {
+				canonical = append([]string(nil), c.aliasPath...)
+			} else {
+				canonical = append(canonical, c.Name)
+			}
+		}
+
+		// return the aliased command itself, so its untoggleable state is checked
+		for c.aliasOf != nil {
+			c = c.aliasOf
+		}
+		return c, canonical
+	}
+
+	for _, g := range r.slashGroups() {
+		if !strings.EqualFold(g.Name, path[0]) || len(path) < 2 {
+			continue
+		}
+
+		canonical := []string{g.Name}
+		rest := path[1:]
+		if sub := g.subgroup(strings.ToLower(rest[0])); sub != nil && len(rest) > 1 {
+			g = sub
+			canonical = append(canonical, sub.Name)
+			rest = rest[1:]
+		}
+		if len(rest) != 1 {
+			continue
+		}
+
+		if c, ok := g.commandMap()[strings.ToLower(rest[0])]; ok {
+			return c, append(canonical, c.Name)
+		}
+	}
+	return nil, nil
+}
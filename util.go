@@ -1,6 +1,9 @@
 package bcr
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/starshine-sys/snowflake/v2"
@@ -16,3 +19,23 @@ func snowflakeInSlice(s snowflake.Snowflake, slice []snowflake.Snowflake) bool {
 	}
 	return false
 }
+
+// writeFileAtomic writes data to a temporary file, and then replaces the file at path with it,
+// so other readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}